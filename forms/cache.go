@@ -0,0 +1,57 @@
+package forms
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/ChristianSiegert/go-packages/html/elements"
+)
+
+// Cache stores elements created by Form, so forms that are rendered many times
+// with the same fields, e.g. a filter form on a list page, do not create the
+// same elements again. An element is only cached and taken from the cache if
+// the field was not submitted and has no validation error. Callers receive a
+// clone of the cached element and may modify it.
+//
+// Elements depend on the form’s ValidationItems and StateClasses, so a Cache
+// must only be shared by forms with the same validation rules and state
+// classes. A Cache is safe for concurrent use.
+type Cache struct {
+	elements map[string]*elements.Element
+	mutex    sync.RWMutex
+}
+
+// NewCache returns a new instance of Cache.
+func NewCache() *Cache {
+	return &Cache{
+		elements: make(map[string]*elements.Element),
+	}
+}
+
+// cached returns a clone of the element cached for kind, fieldName,
+// placeholder and attributes. If there is none, it is created with create. If
+// the form has no cache, or the field was submitted or has a validation error,
+// create is called without caching.
+func (f *Form) cached(kind, fieldName, placeholder string, attributes []string, create func() *elements.Element) *elements.Element {
+	if f.Cache == nil || f.HasError(fieldName) {
+		return create()
+	} else if _, isPosted := f.postedValue(fieldName); isPosted {
+		return create()
+	}
+
+	key := strings.Join(append([]string{kind, fieldName, placeholder}, attributes...), "\x00")
+
+	f.Cache.mutex.RLock()
+	element, ok := f.Cache.elements[key]
+	f.Cache.mutex.RUnlock()
+
+	if !ok {
+		element = create()
+
+		f.Cache.mutex.Lock()
+		f.Cache.elements[key] = element
+		f.Cache.mutex.Unlock()
+	}
+
+	return element.Clone()
+}
@@ -0,0 +1,54 @@
+package forms
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestForm_Cache(t *testing.T) {
+	cache := NewCache()
+
+	newForm := func(url string) *Form {
+		request, err := http.NewRequest("GET", url, &bytes.Buffer{})
+		if err != nil {
+			t.Fatalf("Creating request failed unexpectedly: %s", err)
+		}
+		form := New(request)
+		form.Cache = cache
+		return form
+	}
+
+	first := newForm("/").Text("query", "Search")
+	second := newForm("/").Text("query", "Search")
+
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Expected\n%+v\ngot\n%+v", first, second)
+	} else if first == second {
+		t.Errorf("Expected cached element to be cloned.")
+	} else if len(cache.elements) != 1 {
+		t.Errorf("Expected 1 cached element, got %d", len(cache.elements))
+	}
+
+	// Modifying a returned element must not affect the cache.
+	second.Attributes["class"] = "foo"
+	if third := newForm("/").Input("query", "Search"); third.Attributes["class"] != "" {
+		t.Errorf("Expected cached element to be unchanged, got %+v", third)
+	}
+
+	// Fields with a submitted value or a validation error are not cached.
+	if element := newForm("/?query=foo").Input("query", "Search"); element.Attributes["value"] != "foo" {
+		t.Errorf("Expected value %q, got %+v", "foo", element)
+	}
+
+	form := newForm("/")
+	form.ValidationMessages["query"] = "invalid"
+	if element := form.Textarea("query", "Search"); element.Attributes["aria-invalid"] != "true" {
+		t.Errorf("Expected invalid element, got %+v", element)
+	}
+
+	if len(cache.elements) != 1 {
+		t.Errorf("Expected 1 cached element, got %d", len(cache.elements))
+	}
+}
@@ -2,6 +2,7 @@
 package forms
 
 import (
+	"html/template"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,10 +11,82 @@ import (
 	"github.com/ChristianSiegert/go-packages/validation"
 )
 
+// hexColorPattern and hexColorAlphaPattern are the values of the pattern
+// attribute of hex color fields. They correspond with the rule added by
+// validation.Item.HexColor.
+const (
+	hexColorPattern      = `#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})`
+	hexColorAlphaPattern = `#([0-9a-fA-F]{3}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})`
+)
+
+// telPattern is the value of the pattern attribute of phone number fields. It
+// corresponds with the rule added by validation.Item.Phone.
+const telPattern = `\+?[0-9 \(\)\-]+`
+
+// StateClasses contains the CSS classes that input, select and textarea
+// elements get depending on the validation state of their field. An empty
+// string adds no class.
+type StateClasses struct {
+	// Invalid is added if the field’s value is invalid.
+	Invalid string
+
+	// Pristine is added if the field was not submitted.
+	Pristine string
+
+	// Valid is added if the field was submitted and its value is valid.
+	Valid string
+}
+
+// DefaultStateClasses are the state classes of forms returned by New. Only
+// invalid fields get a class.
+var DefaultStateClasses = StateClasses{
+	Invalid: "error",
+}
+
+// CSRFFieldName is the name of the hidden input element returned by CSRF.
+var CSRFFieldName = "csrf_token"
+
 // Form represents an HTML form.
 type Form struct {
+	// CSRFToken, if not empty, is the token for protecting the form against
+	// cross-site request forgery, see sessions.Session.CSRFToken. Render adds
+	// it as hidden input element. pages.Page sets it to the token of the
+	// page’s session.
+	CSRFToken string
+
+	// CSRFTokenFunc, if not nil, is called by CSRF if CSRFToken is empty, and
+	// its result is stored in CSRFToken. This lets the token be created only
+	// if a form actually needs it. pages.Page uses it to generate the token
+	// of the page’s session on demand.
+	CSRFTokenFunc func() string
+
+	// Cache, if not nil, is used by Input and Textarea to reuse elements
+	// created for the same field name, placeholder and attributes. See Cache.
+	Cache *Cache
+
+	// NoValidate determines whether Render adds the novalidate attribute, so
+	// browsers submit the form without validating it and only the server
+	// validates.
+	NoValidate bool
+
 	request *http.Request
 
+	// StateClasses are the CSS classes for the fields’ validation states,
+	// e.g. StateClasses{Invalid: "is-invalid", Valid: "is-valid"} for
+	// Bootstrap.
+	StateClasses StateClasses
+
+	// TrimValues determines whether Input and Textarea remove leading and
+	// trailing whitespace from submitted values when repopulating fields.
+	// Fields in UntrimmedFields are never trimmed. New sets TrimValues to
+	// true.
+	TrimValues bool
+
+	// UntrimmedFields contains the names of fields whose submitted value is
+	// repopulated as is, e.g. because leading or trailing whitespace is
+	// meaningful.
+	UntrimmedFields map[string]bool
+
 	// ValidationItems is a map of field names and their corresponding
 	// validation.Item. Used to get information about the items’ validation
 	// rules.
@@ -28,12 +101,15 @@ type Form struct {
 func New(request *http.Request) *Form {
 	return &Form{
 		request:            request,
+		StateClasses:       DefaultStateClasses,
+		TrimValues:         true,
 		ValidationMessages: validation.Messages{},
 	}
 }
 
 // Error returns a <div class="validation-error"> element that contains the
-// validation error message as text.
+// validation error message as text. The element’s id is the field name
+// followed by “-error”, so input elements can refer to it.
 func (f *Form) Error(fieldName string) *elements.Element {
 	validationMessage, ok := f.ValidationMessages[fieldName]
 	if !ok || validationMessage == "" {
@@ -43,6 +119,7 @@ func (f *Form) Error(fieldName string) *elements.Element {
 	element := &elements.Element{
 		Attributes: map[string]string{
 			"class": "validation-error",
+			"id":    errorID(fieldName),
 		},
 		HasEndTag: true,
 		TagName:   "div",
@@ -57,8 +134,81 @@ func (f *Form) HasError(fieldName string) bool {
 	return ok
 }
 
-// Input returns an <input> element.
+// setErrorAttributes adds the class for the field’s validation state to
+// element, see StateClasses. If the field’s value is invalid, it also sets
+// aria-invalid and, if there is a validation error message, aria-describedby
+// referring to the element returned by Error.
+func (f *Form) setErrorAttributes(element *elements.Element, fieldName string) {
+	if !f.HasError(fieldName) {
+		class := f.StateClasses.Pristine
+		if _, isPosted := f.postedValue(fieldName); isPosted {
+			class = f.StateClasses.Valid
+		}
+		if class != "" {
+			element.AddAttributeValue("class", class)
+		}
+		return
+	}
+
+	if f.StateClasses.Invalid != "" {
+		element.AddAttributeValue("class", f.StateClasses.Invalid)
+	}
+	element.Attributes["aria-invalid"] = "true"
+
+	if f.ValidationMessages[fieldName] != "" {
+		element.Attributes["aria-describedby"] = errorID(fieldName)
+	}
+}
+
+// postedValue returns the first submitted value of the field. isPosted is true
+// if the field was submitted, even if its value is empty.
+func (f *Form) postedValue(fieldName string) (value string, isPosted bool) {
+	// FormValue parses the submitted form if it was not parsed yet.
+	f.request.FormValue(fieldName)
+
+	if values := f.request.Form[fieldName]; len(values) > 0 {
+		return values[0], true
+	}
+	return "", false
+}
+
+// setLengthAttributes sets the minlength and maxlength attributes of element
+// according to rule, a rule of type validation.RuleTypeLengthBetween.
+func setLengthAttributes(element *elements.Element, rule *validation.Rule) {
+	if minLength, ok := rule.Args[0].(int); ok && minLength > 0 {
+		element.Attributes["minlength"] = strconv.FormatUint(uint64(minLength), 10)
+	}
+	if maxLength, ok := rule.Args[1].(int); ok && maxLength > 0 {
+		element.Attributes["maxlength"] = strconv.FormatUint(uint64(maxLength), 10)
+	}
+}
+
+// echoValue returns the submitted value of the field for repopulating the
+// field, trimmed according to f.TrimValues and f.UntrimmedFields.
+func (f *Form) echoValue(fieldName, value string) string {
+	if !f.TrimValues || f.UntrimmedFields[fieldName] {
+		return value
+	}
+	return strings.TrimSpace(value)
+}
+
+// errorID returns the id of the element returned by Error.
+func errorID(fieldName string) string {
+	return fieldName + "-error"
+}
+
+// Input returns an <input> element. If the field was submitted, its value
+// attribute is set to the submitted value, even if the value is empty or
+// “0”, so the field is repopulated as the user left it. Leading and trailing
+// whitespace is removed, see TrimValues.
 func (f *Form) Input(fieldName, placeholder string, attributes ...string) *elements.Element {
+	return f.cached("input", fieldName, placeholder, attributes, func() *elements.Element {
+		return f.input(fieldName, placeholder, attributes...)
+	})
+}
+
+// input creates the element returned by Input.
+func (f *Form) input(fieldName, placeholder string, attributes ...string) *elements.Element {
 	element := &elements.Element{
 		Attributes: map[string]string{
 			"id":   fieldName,
@@ -67,20 +217,23 @@ func (f *Form) Input(fieldName, placeholder string, attributes ...string) *eleme
 		TagName: "input",
 	}
 
-	if f.HasError(fieldName) {
-		element.Attributes["class"] = "error"
-	}
+	f.setErrorAttributes(element, fieldName)
 
 	if placeholder != "" {
 		element.Attributes["placeholder"] = placeholder
 	}
 
-	if value := f.request.FormValue(fieldName); value != "" {
-		element.Attributes["value"] = strings.TrimSpace(value)
+	// A submitted value is used even if it is empty, e.g. because the user
+	// cleared the field. It replaces a value passed in attributes.
+	value, isPosted := f.postedValue(fieldName)
+	if isPosted {
+		element.Attributes["value"] = f.echoValue(fieldName, value)
 	}
 
 	for i, length := 0, len(attributes); i < length; i += 2 {
-		if i+1 < length {
+		if isPosted && attributes[i] == "value" {
+			continue
+		} else if i+1 < length {
 			element.AddAttributeValue(attributes[i], attributes[i+1])
 		} else {
 			element.AddAttributeValue(attributes[i], "")
@@ -93,7 +246,10 @@ func (f *Form) Input(fieldName, placeholder string, attributes ...string) *eleme
 
 	if field, ok := f.ValidationItems[fieldName]; ok {
 		for _, rule := range field.Rules {
-			if rule.Type == validation.RuleTypeRequired {
+			// Warnings must not prevent submitting the form.
+			if rule.Severity == validation.SeverityWarning {
+				continue
+			} else if rule.Type == validation.RuleTypeRequired || rule.Type == validation.RuleTypeAccepted {
 				element.Attributes["required"] = ""
 			} else if rule.Type == validation.RuleTypeMaxLength {
 				if maxLength, ok := rule.Args[0].(int); ok && maxLength > 0 {
@@ -103,6 +259,25 @@ func (f *Form) Input(fieldName, placeholder string, attributes ...string) *eleme
 				if minLength, ok := rule.Args[0].(int); ok && minLength > 0 {
 					element.Attributes["minlength"] = strconv.FormatUint(uint64(minLength), 10)
 				}
+			} else if rule.Type == validation.RuleTypeLengthBetween {
+				setLengthAttributes(element, rule)
+			} else if rule.Type == validation.RuleTypeBetween {
+				if min, ok := rule.Args[0].(float64); ok {
+					element.Attributes["min"] = strconv.FormatFloat(min, 'f', -1, 64)
+				}
+				if max, ok := rule.Args[1].(float64); ok {
+					element.Attributes["max"] = strconv.FormatFloat(max, 'f', -1, 64)
+				}
+			} else if rule.Type == validation.RuleTypePhone {
+				element.Attributes["pattern"] = telPattern
+				element.Attributes["type"] = "tel"
+			} else if rule.Type == validation.RuleTypeHexColor {
+				// type="color" is not set, because color pickers only
+				// support “#rrggbb” and would discard other values.
+				element.Attributes["pattern"] = hexColorPattern
+				if allowAlpha, ok := rule.Args[0].(bool); ok && allowAlpha {
+					element.Attributes["pattern"] = hexColorAlphaPattern
+				}
 			}
 		}
 	}
@@ -129,6 +304,25 @@ func (f *Form) Checkbox(fieldName, value string, attributes ...string) *elements
 	return element
 }
 
+// Color returns an <input type="color"> element. Browsers submit its value in
+// the format “#rrggbb”, and show black if the value has another format. A
+// pattern attribute added because of a validation.Item.HexColor rule is
+// removed, because browsers ignore it for color inputs.
+func (f *Form) Color(fieldName string, attributes ...string) *elements.Element {
+	element := f.Input(fieldName, "", attributes...)
+	element.Attributes["type"] = "color"
+	delete(element.Attributes, "pattern")
+	return element
+}
+
+// DateTimeLocal returns an <input type="datetime-local"> element. Browsers
+// submit its value in the format “2006-01-02T15:04”.
+func (f *Form) DateTimeLocal(fieldName, placeholder string, attributes ...string) *elements.Element {
+	element := f.Input(fieldName, placeholder, attributes...)
+	element.Attributes["type"] = "datetime-local"
+	return element
+}
+
 // Email returns an <input type="email"> element.
 func (f *Form) Email(fieldName, placeholder string) *elements.Element {
 	element := f.Input(fieldName, placeholder)
@@ -160,6 +354,21 @@ func (f *Form) Radio(fieldName, value string) *elements.Element {
 	return element
 }
 
+// RadioGroup returns a <label> element for each option that contains the
+// option’s radio button, see Radio, followed by the option’s label. The radio
+// button whose value was submitted is checked.
+func (f *Form) RadioGroup(fieldName string, options []*Option) []*elements.Element {
+	labels := make([]*elements.Element, 0, len(options))
+
+	for _, option := range options {
+		radio := f.Radio(fieldName, option.Value)
+		label := f.Label(radio.Attributes["id"], option.Label)
+		label.Children = []*elements.Element{radio}
+		labels = append(labels, label)
+	}
+	return labels
+}
+
 // Password returns an <input type="password"> element.
 func (f *Form) Password(fieldName, placeholder string, attributes ...string) *elements.Element {
 	element := f.Input(fieldName, placeholder, attributes...)
@@ -181,6 +390,137 @@ func (f *Form) Text(fieldName, placeholder string, attributes ...string) *elemen
 	return element
 }
 
+// Time returns an <input type="time"> element. Browsers submit its value in
+// the format “15:04”, or “15:04:05” if the step attribute is less than 60.
+func (f *Form) Time(fieldName, placeholder string, attributes ...string) *elements.Element {
+	element := f.Input(fieldName, placeholder, attributes...)
+	element.Attributes["type"] = "time"
+	return element
+}
+
+// Field returns a <div class="field"> element that contains a <label> element,
+// an <input> element and, if the field’s value is invalid, the element returned
+// by Error. inputKind is the input element’s type: “email” and “password”
+// return the elements of Email and Password, an empty inputKind returns the
+// element of Text, and any other value is used as the type attribute of the
+// element returned by Input.
+func (f *Form) Field(fieldName, label, placeholder string, inputKind string) *elements.Element {
+	var input *elements.Element
+
+	switch inputKind {
+	case "", "text":
+		input = f.Text(fieldName, placeholder)
+	case "email":
+		input = f.Email(fieldName, placeholder)
+	case "password":
+		input = f.Password(fieldName, placeholder)
+	default:
+		input = f.Input(fieldName, placeholder)
+		input.Attributes["type"] = inputKind
+	}
+
+	element := &elements.Element{
+		Attributes: map[string]string{
+			"class": "field",
+		},
+		Children:  []*elements.Element{f.Label(fieldName, label), input},
+		HasEndTag: true,
+		TagName:   "div",
+	}
+
+	if errorElement := f.Error(fieldName); errorElement != nil {
+		element.Children = append(element.Children, errorElement)
+	}
+	return element
+}
+
+// Label returns a <label> element for the field.
+func (f *Form) Label(fieldName, label string) *elements.Element {
+	return &elements.Element{
+		Attributes: map[string]string{
+			"for": fieldName,
+		},
+		HasEndTag: true,
+		TagName:   "label",
+		Text:      label,
+	}
+}
+
+// CSRF returns an <input type="hidden"> element named CSRFFieldName whose
+// value is f.CSRFToken. If f.CSRFToken is empty, it is set to the result of
+// f.CSRFTokenFunc first. If it is still empty, CSRF returns nil.
+func (f *Form) CSRF() *elements.Element {
+	if f.CSRFToken == "" && f.CSRFTokenFunc != nil {
+		f.CSRFToken = f.CSRFTokenFunc()
+	}
+	if f.CSRFToken == "" {
+		return nil
+	}
+
+	return &elements.Element{
+		Attributes: map[string]string{
+			"name":  CSRFFieldName,
+			"type":  "hidden",
+			"value": f.CSRFToken,
+		},
+		TagName: "input",
+	}
+}
+
+// Render returns a <form> element that contains fields, e.g. elements returned
+// by Field, Input or Select, as HTML code that can be used in templates. Nil
+// fields are skipped. If the form has a CSRF token, the element returned by
+// CSRF is added before the fields. If any field contains an
+// <input type="file"> element, the enctype attribute is set to
+// “multipart/form-data”. If f.NoValidate is true, the novalidate attribute is
+// added.
+func (f *Form) Render(action, method string, fields ...*elements.Element) template.HTML {
+	form := &elements.Element{
+		Attributes: map[string]string{
+			"action": action,
+			"method": strings.ToLower(method),
+		},
+		HasEndTag: true,
+		TagName:   "form",
+	}
+
+	if csrf := f.CSRF(); csrf != nil {
+		form.Children = append(form.Children, csrf)
+	}
+
+	for _, field := range fields {
+		if field == nil {
+			continue
+		}
+		if hasFileInput(field) {
+			form.Attributes["enctype"] = "multipart/form-data"
+		}
+		form.Children = append(form.Children, field)
+	}
+
+	if f.NoValidate {
+		form.Attributes["novalidate"] = ""
+	}
+	return form.Html()
+}
+
+// hasFileInput returns whether element is or contains an
+// <input type="file"> element.
+func hasFileInput(element *elements.Element) bool {
+	if element == nil {
+		return false
+	} else if element.TagName == "input" && element.Attributes["type"] == "file" {
+		return true
+	}
+
+	for _, child := range element.Children {
+		if hasFileInput(child) {
+			return true
+		}
+	}
+	return false
+}
+
 // Select returns a <select> element.
 func (f *Form) Select(fieldName string, options []*Option) *elements.Element {
 	element := &elements.Element{
@@ -205,10 +545,23 @@ func (f *Form) Select(fieldName string, options []*Option) *elements.Element {
 		}
 	}
 
-	if f.HasError(fieldName) {
-		element.Attributes["class"] = "error"
-	}
+	f.setErrorAttributes(element, fieldName)
+
+	return element
+}
 
+// Meter returns a <meter> element that displays value as a gauge between min
+// and max, e.g. the used disk space. It is read-only and not submitted, but
+// has the ID fieldName, so Label can be used for it. Its text, shown by
+// browsers without support for <meter>, is value.
+func (f *Form) Meter(fieldName string, value, min, max float64) *elements.Element {
+	element := elements.Text("meter", formatFloat(value))
+	element.Attributes = map[string]string{
+		"id":    fieldName,
+		"max":   formatFloat(max),
+		"min":   formatFloat(min),
+		"value": formatFloat(value),
+	}
 	return element
 }
 
@@ -224,8 +577,32 @@ func (f *Form) Option(value, label string) *elements.Element {
 	}
 }
 
+// Progress returns a <progress> element that displays the completion of a
+// task, value out of max. It is read-only and not submitted. Its text, shown
+// by browsers without support for <progress>, is “value/max”.
+func (f *Form) Progress(value, max float64) *elements.Element {
+	element := elements.Text("progress", formatFloat(value)+"/"+formatFloat(max))
+	element.Attributes = map[string]string{
+		"max":   formatFloat(max),
+		"value": formatFloat(value),
+	}
+	return element
+}
+
+// formatFloat formats f for attribute values, e.g. “1.5” or “10”.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
 // Textarea returns a <textarea> element.
 func (f *Form) Textarea(fieldName, placeholder string, attributes ...string) *elements.Element {
+	return f.cached("textarea", fieldName, placeholder, attributes, func() *elements.Element {
+		return f.textarea(fieldName, placeholder, attributes...)
+	})
+}
+
+// textarea creates the element returned by Textarea.
+func (f *Form) textarea(fieldName, placeholder string, attributes ...string) *elements.Element {
 	element := &elements.Element{
 		Attributes: map[string]string{
 			"id":   fieldName,
@@ -235,16 +612,14 @@ func (f *Form) Textarea(fieldName, placeholder string, attributes ...string) *el
 		TagName:   "textarea",
 	}
 
-	if f.HasError(fieldName) {
-		element.Attributes["class"] = "error"
-	}
+	f.setErrorAttributes(element, fieldName)
 
 	if placeholder != "" {
 		element.Attributes["placeholder"] = placeholder
 	}
 
-	if value := f.request.FormValue(fieldName); value != "" {
-		element.Text = strings.TrimSpace(value)
+	if value, isPosted := f.postedValue(fieldName); isPosted {
+		element.Text = f.echoValue(fieldName, value)
 	}
 
 	for i, length := 0, len(attributes); i < length; i += 2 {
@@ -261,7 +636,10 @@ func (f *Form) Textarea(fieldName, placeholder string, attributes ...string) *el
 
 	if field, ok := f.ValidationItems[fieldName]; ok {
 		for _, rule := range field.Rules {
-			if rule.Type == validation.RuleTypeRequired {
+			// Warnings must not prevent submitting the form.
+			if rule.Severity == validation.SeverityWarning {
+				continue
+			} else if rule.Type == validation.RuleTypeRequired {
 				element.Attributes["required"] = ""
 			} else if rule.Type == validation.RuleTypeMaxLength {
 				if maxLength, ok := rule.Args[0].(int); ok && maxLength > 0 {
@@ -271,6 +649,8 @@ func (f *Form) Textarea(fieldName, placeholder string, attributes ...string) *el
 				if minLength, ok := rule.Args[0].(int); ok && minLength > 0 {
 					element.Attributes["minlength"] = strconv.FormatUint(uint64(minLength), 10)
 				}
+			} else if rule.Type == validation.RuleTypeLengthBetween {
+				setLengthAttributes(element, rule)
 			}
 		}
 	}
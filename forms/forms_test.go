@@ -2,13 +2,12 @@ package forms
 
 import (
 	"bytes"
-	"errors"
 	"net/http"
 	"reflect"
-	"strings"
 	"testing"
 
 	"github.com/ChristianSiegert/go-packages/html/elements"
+	"github.com/ChristianSiegert/go-packages/validation"
 )
 
 func TestForm_Email(t *testing.T) {
@@ -27,63 +26,35 @@ func TestForm_Email(t *testing.T) {
 
 	form1 := New(request1)
 	form2 := New(request2)
-	form2.ValidationRuleMap = map[string][]*ValidationRule{
-		"foo": []*ValidationRule{
-			&ValidationRule{
-				Rule: Required(),
-			},
-			&ValidationRule{
-				Rule: MaxLength(60),
-			},
-			&ValidationRule{
-				Rule: MinLength(12),
-			},
-		},
-	}
+	form2.ValidationItems = validation.New()
+	form2.ValidationItems.Add("foo", "foo@example.com").
+		Required("").
+		MaxLength(60, "").
+		MinLength(12, "")
 
 	tests := []struct {
 		form        *Form
 		name        string
 		placeholder string
-		expected    *elements.Element
+		expected    string
 	}{
 		{
 			form:        form1,
 			name:        "foo",
 			placeholder: "",
-			expected: &elements.Element{
-				Attributes: map[string]string{
-					"id":        "foo",
-					"maxlength": "254",
-					"name":      "foo",
-					"type":      "email",
-				},
-				Tag: "input",
-			},
+			expected:    `<input id="foo" maxlength="254" name="foo" type="email">`,
 		},
 		{
 			form:        form2,
 			name:        "foo",
 			placeholder: "bar",
-			expected: &elements.Element{
-				Attributes: map[string]string{
-					"id":          "foo",
-					"maxlength":   "60",
-					"minlength":   "12",
-					"name":        "foo",
-					"placeholder": "bar",
-					"required":    "",
-					"type":        "email",
-					"value":       "foo@example.com",
-				},
-				Tag: "input",
-			},
+			expected:    `<input id="foo" maxlength="60" minlength="12" name="foo" placeholder="bar" required type="email" value="foo@example.com">`,
 		},
 	}
 
 	for i, test := range tests {
-		if result := test.form.Email(test.name, test.placeholder); !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("Test %d returned\n%+v\nexpected\n%+v", i+1, result, test.expected)
+		if result := test.form.Email(test.name, test.placeholder).String(); result != test.expected {
+			t.Errorf("Test %d returned\n%s\nexpected\n%s", i+1, result, test.expected)
 		}
 	}
 }
@@ -94,7 +65,7 @@ func TestForm_Error(t *testing.T) {
 		t.Fatalf("Creating request failed unexpectedly: %s", err)
 	}
 	form1 := New(request)
-	form1.ErrorMessageMap = map[string]string{
+	form1.ValidationMessages = validation.Messages{
 		"foo": "foo error",
 	}
 
@@ -109,9 +80,10 @@ func TestForm_Error(t *testing.T) {
 			expected: &elements.Element{
 				Attributes: map[string]string{
 					"class": "validation-error",
+					"id":    "foo-error",
 				},
 				HasEndTag: true,
-				Tag:       "div",
+				TagName:   "div",
 				Text:      "foo error",
 			},
 		},
@@ -131,7 +103,7 @@ func TestForm_Error(t *testing.T) {
 
 func TestForm_HasError(t *testing.T) {
 	form := New(nil)
-	form.ErrorMessageMap = map[string]string{
+	form.ValidationMessages = validation.Messages{
 		"foo": "foo error",
 	}
 
@@ -175,60 +147,35 @@ func TestForm_Input(t *testing.T) {
 
 	form1 := New(request1)
 	form2 := New(request2)
-	form2.ValidationRuleMap = map[string][]*ValidationRule{
-		"foo": []*ValidationRule{
-			&ValidationRule{
-				Rule: Required(),
-			},
-			&ValidationRule{
-				Rule: MaxLength(80),
-			},
-			&ValidationRule{
-				Rule: MinLength(3),
-			},
-		},
-	}
+	form2.ValidationItems = validation.New()
+	form2.ValidationItems.Add("foo", "Hello, world!").
+		Required("").
+		MaxLength(80, "").
+		MinLength(3, "")
 
 	tests := []struct {
 		form        *Form
 		fieldName   string
 		placeholder string
-		expected    *elements.Element
+		expected    string
 	}{
 		{
 			form:        form1,
 			fieldName:   "foo",
 			placeholder: "",
-			expected: &elements.Element{
-				Attributes: map[string]string{
-					"id":   "foo",
-					"name": "foo",
-				},
-				Tag: "input",
-			},
+			expected:    `<input id="foo" name="foo">`,
 		},
 		{
 			form:        form2,
 			fieldName:   "foo",
 			placeholder: "bar",
-			expected: &elements.Element{
-				Attributes: map[string]string{
-					"id":          "foo",
-					"maxlength":   "80",
-					"minlength":   "3",
-					"name":        "foo",
-					"placeholder": "bar",
-					"required":    "",
-					"value":       "Hello, world!",
-				},
-				Tag: "input",
-			},
+			expected:    `<input id="foo" maxlength="80" minlength="3" name="foo" placeholder="bar" required value="Hello, world!">`,
 		},
 	}
 
 	for i, test := range tests {
-		if result := test.form.Input(test.fieldName, test.placeholder); !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("Test %d returned\n%+v\nexpected\n%+v", i+1, result, test.expected)
+		if result := test.form.Input(test.fieldName, test.placeholder).String(); result != test.expected {
+			t.Errorf("Test %d returned\n%s\nexpected\n%s", i+1, result, test.expected)
 		}
 	}
 }
@@ -249,62 +196,35 @@ func TestForm_Password(t *testing.T) {
 
 	form1 := New(request1)
 	form2 := New(request2)
-	form2.ValidationRuleMap = map[string][]*ValidationRule{
-		"foo": []*ValidationRule{
-			&ValidationRule{
-				Rule: Required(),
-			},
-			&ValidationRule{
-				Rule: MaxLength(50),
-			},
-			&ValidationRule{
-				Rule: MinLength(10),
-			},
-		},
-	}
+	form2.ValidationItems = validation.New()
+	form2.ValidationItems.Add("foo", "password123").
+		Required("").
+		MaxLength(50, "").
+		MinLength(10, "")
 
 	tests := []struct {
 		form        *Form
 		name        string
 		placeholder string
-		expected    *elements.Element
+		expected    string
 	}{
 		{
 			form:        form1,
 			name:        "foo",
 			placeholder: "",
-			expected: &elements.Element{
-				Attributes: map[string]string{
-					"id":   "foo",
-					"name": "foo",
-					"type": "password",
-				},
-				Tag: "input",
-			},
+			expected:    `<input id="foo" name="foo" type="password">`,
 		},
 		{
 			form:        form2,
 			name:        "foo",
 			placeholder: "bar",
-			expected: &elements.Element{
-				Attributes: map[string]string{
-					"id":          "foo",
-					"maxlength":   "50",
-					"minlength":   "10",
-					"name":        "foo",
-					"placeholder": "bar",
-					"required":    "",
-					"type":        "password",
-					"value":       "password123",
-				},
-				Tag: "input",
-			},
+			expected:    `<input id="foo" maxlength="50" minlength="10" name="foo" placeholder="bar" required type="password" value="password123">`,
 		},
 	}
 
 	for i, test := range tests {
-		if result := test.form.Password(test.name, test.placeholder); !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("Test %d returned\n%+v\nexpected\n%+v", i+1, result, test.expected)
+		if result := test.form.Password(test.name, test.placeholder).String(); result != test.expected {
+			t.Errorf("Test %d returned\n%s\nexpected\n%s", i+1, result, test.expected)
 		}
 	}
 }
@@ -325,204 +245,601 @@ func TestForm_Textarea(t *testing.T) {
 
 	form1 := New(request1)
 	form2 := New(request2)
-	form2.ValidationRuleMap = map[string][]*ValidationRule{
-		"foo": []*ValidationRule{
-			&ValidationRule{
-				Rule: Required(),
-			},
-			&ValidationRule{
-				Rule: MaxLength(1000),
-			},
-			&ValidationRule{
-				Rule: MinLength(100),
-			},
-		},
-	}
+	form2.ValidationItems = validation.New()
+	form2.ValidationItems.Add("foo", "Hello, world!").
+		Required("").
+		MaxLength(1000, "").
+		MinLength(100, "")
 
 	tests := []struct {
 		form        *Form
 		name        string
 		placeholder string
-		expected    *elements.Element
+		expected    string
 	}{
 		{
 			form:        form1,
 			name:        "foo",
 			placeholder: "",
-			expected: &elements.Element{
-				Attributes: map[string]string{
-					"id":   "foo",
-					"name": "foo",
-				},
-				HasEndTag: true,
-				Tag:       "textarea",
-			},
+			expected:    `<textarea id="foo" name="foo"></textarea>`,
 		},
 		{
 			form:        form2,
 			name:        "foo",
 			placeholder: "bar",
-			expected: &elements.Element{
-				Attributes: map[string]string{
-					"id":          "foo",
-					"maxlength":   "1000",
-					"minlength":   "100",
-					"name":        "foo",
-					"placeholder": "bar",
-					"required":    "",
-				},
-				HasEndTag: true,
-				Tag:       "textarea",
-				Text:      "Hello, world!",
-			},
+			expected:    `<textarea id="foo" maxlength="1000" minlength="100" name="foo" placeholder="bar" required>Hello, world!</textarea>`,
 		},
 	}
 
 	for i, test := range tests {
-		if result := test.form.Textarea(test.name, test.placeholder); !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("Test %d returned\n%+v\nexpected\n%+v", i+1, result, test.expected)
+		if result := test.form.Textarea(test.name, test.placeholder).String(); result != test.expected {
+			t.Errorf("Test %d returned\n%s\nexpected\n%s", i+1, result, test.expected)
 		}
 	}
 }
 
-func TestForm_Validate(t *testing.T) {
-	customTestError := errors.New("test: foo already taken")
+func TestForm_ariaAttributes(t *testing.T) {
+	request, err := http.NewRequest("GET", "/", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Creating request failed unexpectedly: %s", err)
+	}
+
+	form := New(request)
+	form.ValidationMessages = validation.Messages{
+		"foo": "foo error",
+		"bar": "",
+	}
 
 	tests := []struct {
-		requestForm             map[string][]string
-		validationRuleMap       map[string][]*ValidationRule
-		expectedValid           bool
-		expectedErr             error
-		expectedErrorMessageMap map[string]string
+		element  *elements.Element
+		expected map[string]string
 	}{
 		{
-			requestForm:             nil,
-			validationRuleMap:       nil,
-			expectedValid:           false,
-			expectedErr:             ErrNoValidationRules,
-			expectedErrorMessageMap: nil,
+			element: form.Input("foo", ""),
+			expected: map[string]string{
+				"aria-describedby": "foo-error",
+				"aria-invalid":     "true",
+				"class":            "error",
+				"id":               "foo",
+				"name":             "foo",
+			},
 		},
 		{
-			requestForm: map[string][]string{
-				"foo": {strings.Repeat("ä", 5)},
-			},
-			validationRuleMap: map[string][]*ValidationRule{
-				"foo": []*ValidationRule{
-					&ValidationRule{
-						Message: "Foo is already taken.",
-						Rule: &Rule{
-							Func: func(value string) (bool, error) {
-								return false, customTestError
-							},
-						},
-					},
-				},
+			element: form.Input("bar", ""),
+			expected: map[string]string{
+				"aria-invalid": "true",
+				"class":        "error",
+				"id":           "bar",
+				"name":         "bar",
 			},
-			expectedValid:           false,
-			expectedErr:             customTestError,
-			expectedErrorMessageMap: nil,
 		},
 		{
-			requestForm: map[string][]string{
-				"foo": {strings.Repeat("ä", 5)},
-				"bar": {strings.Repeat("ä", 10)},
-			},
-			validationRuleMap: map[string][]*ValidationRule{
-				"foo": []*ValidationRule{
-					&ValidationRule{
-						Message: "Foo must have at least 5 characters.",
-						Rule:    MinLength(5),
-					},
-					&ValidationRule{
-						Message: "Foo must have at most 50 characters",
-						Rule:    MaxLength(50),
-					},
-				},
-				"bar": []*ValidationRule{
-					&ValidationRule{
-						Message: "Bar must have at least 5 characters.",
-						Rule:    MinLength(5),
-					},
-					&ValidationRule{
-						Message: "Bar must have at most 50 characters",
-						Rule:    MaxLength(50),
-					},
-				},
+			element: form.Input("baz", ""),
+			expected: map[string]string{
+				"id":   "baz",
+				"name": "baz",
 			},
-			expectedValid:           true,
-			expectedErr:             nil,
-			expectedErrorMessageMap: nil,
 		},
 		{
-			requestForm: map[string][]string{
-				"foo": {strings.Repeat("ä", 4)},
-				"bar": {strings.Repeat("ä", 10)},
+			element: form.Select("foo", nil),
+			expected: map[string]string{
+				"aria-describedby": "foo-error",
+				"aria-invalid":     "true",
+				"class":            "error",
+				"id":               "foo",
+				"name":             "foo",
 			},
-			validationRuleMap: map[string][]*ValidationRule{
-				"foo": []*ValidationRule{
-					&ValidationRule{
-						Message: "Foo is required.",
-						Rule:    Required(),
-					},
-					&ValidationRule{
-						Message: "Foo must have at least 5 characters.",
-						Rule:    MinLength(5),
-					},
-					&ValidationRule{
-						Message: "Foo must have at most 50 characters",
-						Rule:    MaxLength(50),
-					},
-				},
-				"bar": []*ValidationRule{
-					&ValidationRule{
-						Message: "Bar is required.",
-						Rule:    Required(),
-					},
-					&ValidationRule{
-						Message: "Bar must have at least 5 characters.",
-						Rule:    MinLength(5),
-					},
-					&ValidationRule{
-						Message: "Bar must have at most 50 characters",
-						Rule:    MaxLength(50),
-					},
-				},
-				"baz": []*ValidationRule{
-					&ValidationRule{
-						Message: "Baz is required.",
-						Rule:    Required(),
-					},
-				},
+		},
+		{
+			element: form.Textarea("foo", ""),
+			expected: map[string]string{
+				"aria-describedby": "foo-error",
+				"aria-invalid":     "true",
+				"class":            "error",
+				"id":               "foo",
+				"name":             "foo",
 			},
-			expectedValid: false,
-			expectedErr:   nil,
-			expectedErrorMessageMap: map[string]string{
-				"foo": "Foo must have at least 5 characters.",
-				"baz": "Baz is required.",
+		},
+		{
+			element: form.Error("foo"),
+			expected: map[string]string{
+				"class": "validation-error",
+				"id":    "foo-error",
 			},
 		},
 	}
 
 	for i, test := range tests {
-		request, err := http.NewRequest("GET", "/", &bytes.Buffer{})
+		if !reflect.DeepEqual(test.element.Attributes, test.expected) {
+			t.Errorf("Test %d returned\n%+v\nexpected\n%+v", i+1, test.element.Attributes, test.expected)
+		}
+	}
+}
+
+func TestForm_Input_phone(t *testing.T) {
+	request, err := http.NewRequest("GET", "/", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Creating request failed unexpectedly: %s", err)
+	}
+
+	form := New(request)
+	form.ValidationItems = validation.New()
+	form.ValidationItems.Add("phone", "").Phone("invalid phone number")
+
+	expected := &elements.Element{
+		Attributes: map[string]string{
+			"id":      "phone",
+			"name":    "phone",
+			"pattern": `\+?[0-9 \(\)\-]+`,
+			"type":    "tel",
+		},
+		TagName: "input",
+	}
+
+	if result := form.Input("phone", ""); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected\n%+v\ngot\n%+v", expected, result)
+	}
+}
+
+func TestForm_Field(t *testing.T) {
+	request, err := http.NewRequest("GET", "/", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Creating request failed unexpectedly: %s", err)
+	}
+
+	form := New(request)
+	form.ValidationMessages["email"] = "invalid email address"
+
+	tests := []struct {
+		element  *elements.Element
+		expected string
+	}{
+		{
+			element:  form.Field("name", "Name", "", ""),
+			expected: `<div class="field"><label for="name">Name</label><input id="name" name="name" type="text"></div>`,
+		},
+		{
+			element:  form.Field("password", "Password", "", "password"),
+			expected: `<div class="field"><label for="password">Password</label><input id="password" name="password" type="password"></div>`,
+		},
+		{
+			element:  form.Field("url", "Website", "https://", "url"),
+			expected: `<div class="field"><label for="url">Website</label><input id="url" name="url" placeholder="https://" type="url"></div>`,
+		},
+		{
+			element:  form.Field("email", "Email", "", "email"),
+			expected: `<div class="field"><label for="email">Email</label><input aria-describedby="email-error" aria-invalid="true" class="error" id="email" maxlength="254" name="email" type="email"><div class="validation-error" id="email-error">invalid email address</div></div>`,
+		},
+	}
+
+	for i, test := range tests {
+		if result := test.element.String(); result != test.expected {
+			t.Errorf("Test %d returned\n%s\nexpected\n%s", i+1, result, test.expected)
+		}
+	}
+}
+
+func TestForm_Checkbox_accepted(t *testing.T) {
+	request, err := http.NewRequest("GET", "/", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Creating request failed unexpectedly: %s", err)
+	}
+
+	form := New(request)
+	form.ValidationItems = validation.New()
+	form.ValidationItems.Add("terms", "").Accepted("terms must be accepted")
+
+	expected := `<input id="terms-yes" name="terms" required type="checkbox" value="yes">`
+	if result := form.Checkbox("terms", "yes").String(); result != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, result)
+	}
+}
+
+func TestForm_Input_postedValue(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"/", `<input id="qty" name="qty" value="1">`},
+		{"/?qty=", `<input id="qty" name="qty" value="">`},
+		{"/?qty=0", `<input id="qty" name="qty" value="0">`},
+		{"/?qty=+5+", `<input id="qty" name="qty" value="5">`},
+	}
+
+	for i, test := range tests {
+		request, err := http.NewRequest("GET", test.url, &bytes.Buffer{})
 		if err != nil {
 			t.Fatalf("Creating request failed unexpectedly: %s", err)
 		}
 
-		request.Form = test.requestForm
-		form := New(request)
-		form.ValidationRuleMap = test.validationRuleMap
+		if result := New(request).Input("qty", "", "value", "1").String(); result != test.expected {
+			t.Errorf("Test %d returned\n%s\nexpected\n%s", i+1, result, test.expected)
+		}
+	}
+}
 
-		expectedForm := New(request)
-		expectedForm.ValidationRuleMap = test.validationRuleMap
-		expectedForm.ErrorMessageMap = test.expectedErrorMessageMap
+func TestForm_Time(t *testing.T) {
+	request, err := http.NewRequest("GET", "/?start=09:30", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Creating request failed unexpectedly: %s", err)
+	}
+
+	form := New(request)
+	form.ValidationMessages["end"] = "invalid time"
 
-		if result, err := form.Validate(); err != test.expectedErr {
-			t.Errorf("Test %d: form.Validate() returned error %q, expected error %q.", i+1, err, test.expectedErr)
-		} else if !reflect.DeepEqual(form, expectedForm) {
-			t.Errorf("Test %d: form and expected form differ:\n%+v\n%+v", i+1, form, expectedForm)
-		} else if !reflect.DeepEqual(result, test.expectedValid) {
-			t.Errorf("Test %d form.Validate() returned %t, expected %t.", i+1, result, test.expectedValid)
+	tests := []struct {
+		element  *elements.Element
+		expected string
+	}{
+		{
+			element:  form.Time("start", "", "min", "08:00", "max", "18:00"),
+			expected: `<input id="start" max="18:00" min="08:00" name="start" type="time" value="09:30">`,
+		},
+		{
+			element:  form.Time("end", ""),
+			expected: `<input aria-describedby="end-error" aria-invalid="true" class="error" id="end" name="end" type="time">`,
+		},
+		{
+			element:  form.DateTimeLocal("meeting", "", "min", "2026-01-01T00:00"),
+			expected: `<input id="meeting" min="2026-01-01T00:00" name="meeting" type="datetime-local">`,
+		},
+	}
+
+	for i, test := range tests {
+		if result := test.element.String(); result != test.expected {
+			t.Errorf("Test %d returned\n%s\nexpected\n%s", i+1, result, test.expected)
+		}
+	}
+}
+
+func TestForm_RadioGroup(t *testing.T) {
+	request, err := http.NewRequest("GET", "/?color=green", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Creating request failed unexpectedly: %s", err)
+	}
+
+	form := New(request)
+	form.ValidationMessages["color"] = "invalid color"
+
+	options := []*Option{
+		{Label: "Red", Value: "red"},
+		{Label: "Green", Value: "green"},
+	}
+
+	expected := []string{
+		`<label for="color-red"><input aria-describedby="color-error" aria-invalid="true" class="error" id="color-red" name="color" type="radio" value="red">Red</label>`,
+		`<label for="color-green"><input aria-describedby="color-error" aria-invalid="true" checked class="error" id="color-green" name="color" type="radio" value="green">Green</label>`,
+	}
+
+	result := form.RadioGroup("color", options)
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d elements, got %d", len(expected), len(result))
+	}
+
+	for i, element := range result {
+		if s := element.String(); s != expected[i] {
+			t.Errorf("Element %d is\n%s\nexpected\n%s", i+1, s, expected[i])
+		}
+	}
+}
+
+func TestForm_StateClasses(t *testing.T) {
+	request, err := http.NewRequest("GET", "/?email=foo&name=Jane", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Creating request failed unexpectedly: %s", err)
+	}
+
+	form := New(request)
+	form.StateClasses = StateClasses{Invalid: "is-invalid", Pristine: "is-pristine", Valid: "is-valid"}
+	form.ValidationMessages["email"] = "invalid email address"
+
+	tests := []struct {
+		element  *elements.Element
+		expected string
+	}{
+		{
+			element:  form.Text("email", "", "class", "input"),
+			expected: `<input aria-describedby="email-error" aria-invalid="true" class="is-invalid input" id="email" name="email" type="text" value="foo">`,
+		},
+		{
+			element:  form.Text("name", ""),
+			expected: `<input class="is-valid" id="name" name="name" type="text" value="Jane">`,
+		},
+		{
+			element:  form.Textarea("comment", ""),
+			expected: `<textarea class="is-pristine" id="comment" name="comment"></textarea>`,
+		},
+		{
+			element:  New(request).Text("name", ""),
+			expected: `<input id="name" name="name" type="text" value="Jane">`,
+		},
+	}
+
+	for i, test := range tests {
+		if result := test.element.String(); result != test.expected {
+			t.Errorf("Test %d returned\n%s\nexpected\n%s", i+1, result, test.expected)
+		}
+	}
+}
+
+func TestForm_Input_hexColor(t *testing.T) {
+	request, err := http.NewRequest("GET", "/", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Creating request failed unexpectedly: %s", err)
+	}
+
+	form := New(request)
+	form.ValidationItems = validation.New()
+	form.ValidationItems.Add("color", "").HexColor(false, "invalid color")
+	form.ValidationItems.Add("overlay", "").HexColor(true, "invalid color")
+
+	tests := []struct {
+		element  *elements.Element
+		expected string
+	}{
+		{
+			element:  form.Text("color", ""),
+			expected: `<input id="color" name="color" pattern="#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})" type="text">`,
+		},
+		{
+			element:  form.Text("overlay", ""),
+			expected: `<input id="overlay" name="overlay" pattern="#([0-9a-fA-F]{3}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})" type="text">`,
+		},
+	}
+
+	for i, test := range tests {
+		if result := test.element.String(); result != test.expected {
+			t.Errorf("Test %d returned\n%s\nexpected\n%s", i+1, result, test.expected)
+		}
+	}
+}
+
+func TestForm_rangeAttributes(t *testing.T) {
+	request, err := http.NewRequest("GET", "/", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Creating request failed unexpectedly: %s", err)
+	}
+
+	form := New(request)
+	form.ValidationItems = validation.New()
+	form.ValidationItems.Add("name", "").LengthBetween(2, 20, "2 to 20 characters")
+	form.ValidationItems.Add("bio", "").LengthBetween(0, 500, "at most 500 characters")
+	form.ValidationItems.Add("age", "").Between(18, 99.5, "between 18 and 99.5")
+
+	tests := []struct {
+		element  *elements.Element
+		expected string
+	}{
+		{
+			element:  form.Text("name", ""),
+			expected: `<input id="name" maxlength="20" minlength="2" name="name" type="text">`,
+		},
+		{
+			element:  form.Textarea("bio", ""),
+			expected: `<textarea id="bio" maxlength="500" name="bio"></textarea>`,
+		},
+		{
+			element:  form.Number("age", ""),
+			expected: `<input id="age" max="99.5" min="18" name="age" type="number">`,
+		},
+	}
+
+	for i, test := range tests {
+		if result := test.element.String(); result != test.expected {
+			t.Errorf("Test %d returned\n%s\nexpected\n%s", i+1, result, test.expected)
+		}
+	}
+}
+
+func TestForm_Color(t *testing.T) {
+	request, err := http.NewRequest("GET", "/?color=%23a0b1c2", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Creating request failed unexpectedly: %s", err)
+	}
+
+	form := New(request)
+	form.ValidationItems = validation.New()
+	form.ValidationItems.Add("color", "#a0b1c2").HexColor(false, "invalid color")
+	form.ValidationMessages["background"] = "invalid color"
+
+	tests := []struct {
+		element  *elements.Element
+		expected string
+	}{
+		{
+			element:  form.Color("color"),
+			expected: `<input id="color" name="color" type="color" value="#a0b1c2">`,
+		},
+		{
+			element:  form.Color("background", "value", "#ffffff"),
+			expected: `<input aria-describedby="background-error" aria-invalid="true" class="error" id="background" name="background" type="color" value="#ffffff">`,
+		},
+	}
+
+	for i, test := range tests {
+		if result := test.element.String(); result != test.expected {
+			t.Errorf("Test %d returned\n%s\nexpected\n%s", i+1, result, test.expected)
+		}
+	}
+}
+
+func TestForm_Repeatable(t *testing.T) {
+	request, err := http.NewRequest("GET", "/?items[2].name=b&items[0].name=a&items[0].qty=1&items[x].name=c&other=d", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Creating request failed unexpectedly: %s", err)
+	}
+
+	form := New(request)
+	form.ValidationMessages["items[2].name"] = "too short"
+
+	expected := []*Repetition{
+		{Index: 0, Prefix: "items"},
+		{Index: 2, Prefix: "items"},
+		{Index: 3, IsNew: true, Prefix: "items"},
+	}
+	repetitions := form.Repeatable("items")
+	if !reflect.DeepEqual(repetitions, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, repetitions)
+	}
+
+	tests := []struct {
+		element  *elements.Element
+		expected string
+	}{
+		{
+			element:  form.Text(repetitions[0].Name("name"), ""),
+			expected: `<input id="items[0].name" name="items[0].name" type="text" value="a">`,
+		},
+		{
+			element:  form.Text(repetitions[1].Name("name"), ""),
+			expected: `<input aria-describedby="items[2].name-error" aria-invalid="true" class="error" id="items[2].name" name="items[2].name" type="text" value="b">`,
+		},
+		{
+			element:  form.Text(repetitions[2].Name("name"), ""),
+			expected: `<input id="items[3].name" name="items[3].name" type="text">`,
+		},
+	}
+
+	for i, test := range tests {
+		if result := test.element.String(); result != test.expected {
+			t.Errorf("Test %d returned\n%s\nexpected\n%s", i+1, result, test.expected)
+		}
+	}
+
+	if result := New(request).Repeatable("rows"); len(result) != 1 || result[0].Index != 0 || !result[0].IsNew {
+		t.Errorf("Expected one new repetition with index 0, got %+v", result)
+	}
+}
+
+func TestForm_Render(t *testing.T) {
+	request, err := http.NewRequest("GET", "/", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Creating request failed unexpectedly: %s", err)
+	}
+
+	form := New(request)
+	file := form.Input("avatar", "")
+	file.Attributes["type"] = "file"
+
+	tests := []struct {
+		csrfToken  string
+		noValidate bool
+		fields     []*elements.Element
+		expected   string
+	}{
+		{
+			fields:   []*elements.Element{form.Text("name", ""), nil},
+			expected: `<form action="/save" method="post"><input id="name" name="name" type="text"></form>`,
+		},
+		{
+			csrfToken:  "abc",
+			noValidate: true,
+			fields:     []*elements.Element{elements.Text("div", "").Append(file)},
+			expected:   `<form action="/save" enctype="multipart/form-data" method="post" novalidate><input name="csrf_token" type="hidden" value="abc"><div><input id="avatar" name="avatar" type="file"></div></form>`,
+		},
+	}
+
+	for i, test := range tests {
+		form.CSRFToken = test.csrfToken
+		form.NoValidate = test.noValidate
+		if result := form.Render("/save", "POST", test.fields...); string(result) != test.expected {
+			t.Errorf("Test %d returned\n%s\nexpected\n%s", i+1, result, test.expected)
+		}
+	}
+}
+
+func TestForm_CSRF_tokenFunc(t *testing.T) {
+	form := New(nil)
+	calls := 0
+	form.CSRFTokenFunc = func() string {
+		calls++
+		return "abc"
+	}
+
+	if calls != 0 {
+		t.Fatal("Expected CSRFTokenFunc not to be called before CSRF.")
+	}
+
+	for i := 0; i < 2; i++ {
+		if result, expected := form.CSRF().String(), `<input name="csrf_token" type="hidden" value="abc">`; result != expected {
+			t.Errorf("Expected %q, got %q", expected, result)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected CSRFTokenFunc to be called once, got %d calls", calls)
+	}
+
+	form = New(nil)
+	form.CSRFTokenFunc = func() string { return "" }
+	if element := form.CSRF(); element != nil {
+		t.Errorf("Expected nil, got %s", element)
+	}
+}
+
+func TestForm_TrimValues(t *testing.T) {
+	request, err := http.NewRequest("GET", "/?name=+Jane+&token=+abc+&comment=+foo%0A", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Creating request failed unexpectedly: %s", err)
+	}
+
+	form := New(request)
+	form.UntrimmedFields = map[string]bool{"token": true}
+
+	tests := []struct {
+		element  *elements.Element
+		expected string
+	}{
+		{form.Text("name", ""), `<input id="name" name="name" type="text" value="Jane">`},
+		{form.Text("token", ""), `<input id="token" name="token" type="text" value=" abc ">`},
+		{form.Textarea("comment", ""), "<textarea id=\"comment\" name=\"comment\">foo</textarea>"},
+	}
+
+	form = New(request)
+	form.TrimValues = false
+	tests = append(tests, []struct {
+		element  *elements.Element
+		expected string
+	}{
+		{form.Text("name", ""), `<input id="name" name="name" type="text" value=" Jane ">`},
+		{form.Textarea("comment", ""), "<textarea id=\"comment\" name=\"comment\"> foo\n</textarea>"},
+	}...)
+
+	for i, test := range tests {
+		if result := test.element.String(); result != test.expected {
+			t.Errorf("Test %d returned\n%s\nexpected\n%s", i+1, result, test.expected)
+		}
+	}
+}
+
+func TestForm_Meter_Progress(t *testing.T) {
+	request, err := http.NewRequest("GET", "/", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Creating request failed unexpectedly: %s", err)
+	}
+
+	form := New(request)
+
+	tests := []struct {
+		element  *elements.Element
+		expected string
+	}{
+		{
+			element:  form.Meter("disk", 0.75, 0, 1),
+			expected: `<meter id="disk" max="1" min="0" value="0.75">0.75</meter>`,
+		},
+		{
+			element:  form.Meter("temperature", -5, -20, 40),
+			expected: `<meter id="temperature" max="40" min="-20" value="-5">-5</meter>`,
+		},
+		{
+			element:  form.Progress(30, 120),
+			expected: `<progress max="120" value="30">30/120</progress>`,
+		},
+	}
+
+	for i, test := range tests {
+		if result := test.element.String(); result != test.expected {
+			t.Errorf("Test %d returned\n%s\nexpected\n%s", i+1, result, test.expected)
 		}
 	}
 }
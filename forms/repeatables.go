@@ -0,0 +1,86 @@
+package forms
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Repetition is an instance of a repeatable group of fields, see Repeatable.
+type Repetition struct {
+	// Index is the instance’s index in the field names.
+	Index int
+
+	// IsNew is true for the empty instance that follows the submitted ones.
+	IsNew bool
+
+	// Prefix is the name of the group, e.g. “items”.
+	Prefix string
+}
+
+// Name returns the indexed name of a field of the instance, e.g.
+// “items[0].name”. Fields created with this name are repopulated with their
+// submitted value and marked as invalid if ValidationMessages contains the
+// indexed name, e.g. {{.Form.Text ($row.Name "name") ""}}.
+func (r *Repetition) Name(fieldName string) string {
+	return r.Prefix + "[" + strconv.Itoa(r.Index) + "]." + fieldName
+}
+
+// Repeatable returns an instance of the field group prefix for each index that
+// was submitted, followed by one empty instance, so templates can render
+// “add another” lists of fields, e.g.
+//
+//	{{range $row := .Form.Repeatable "items"}}
+//		{{$.Form.Text ($row.Name "name") ""}}
+//		{{$.Form.Error ($row.Name "name")}}
+//	{{end}}
+//
+// Submitted instances keep their index, so validation messages keyed by
+// indexed names match. They are ordered by index. The empty instance’s index is
+// one larger than the largest submitted index. Field names have the form that
+// params.Parser.Parse parses into slices of structs.
+func (f *Form) Repeatable(prefix string) []*Repetition {
+	// FormValue parses the submitted form if it was not parsed yet.
+	f.request.FormValue(prefix)
+
+	indexes := make(map[int]bool)
+	for key := range f.request.Form {
+		if index, ok := repetitionIndex(key, prefix); ok {
+			indexes[index] = true
+		}
+	}
+
+	repetitions := make([]*Repetition, 0, len(indexes)+1)
+	next := 0
+	for index := range indexes {
+		repetitions = append(repetitions, &Repetition{Index: index, Prefix: prefix})
+		if index >= next {
+			next = index + 1
+		}
+	}
+	sort.Slice(repetitions, func(i, j int) bool {
+		return repetitions[i].Index < repetitions[j].Index
+	})
+
+	return append(repetitions, &Repetition{Index: next, IsNew: true, Prefix: prefix})
+}
+
+// repetitionIndex returns the index of a field name of the form
+// “prefix[index].name”. ok is false if key does not have this form.
+func repetitionIndex(key, prefix string) (index int, ok bool) {
+	if !strings.HasPrefix(key, prefix+"[") {
+		return 0, false
+	}
+	rest := key[len(prefix)+1:]
+
+	end := strings.Index(rest, "].")
+	if end < 1 || end+2 == len(rest) {
+		return 0, false
+	}
+
+	index, err := strconv.Atoi(rest[:end])
+	if err != nil || index < 0 {
+		return 0, false
+	}
+	return index, true
+}
@@ -8,6 +8,40 @@ import (
 	"strings"
 )
 
+// BooleanAttributes contains the names of boolean attributes. A boolean
+// attribute with an empty value is rendered without value, e.g. “required”,
+// whereas other attributes with an empty value are rendered with an empty
+// value, e.g. `value=""`. Names can be added or removed to change how
+// attributes are rendered.
+var BooleanAttributes = map[string]bool{
+	"allowfullscreen": true,
+	"async":           true,
+	"autofocus":       true,
+	"autoplay":        true,
+	"checked":         true,
+	"controls":        true,
+	"default":         true,
+	"defer":           true,
+	"disabled":        true,
+	"formnovalidate":  true,
+	"hidden":          true,
+	"inert":           true,
+	"ismap":           true,
+	"itemscope":       true,
+	"loop":            true,
+	"multiple":        true,
+	"muted":           true,
+	"nomodule":        true,
+	"novalidate":      true,
+	"open":            true,
+	"playsinline":     true,
+	"readonly":        true,
+	"required":        true,
+	"reversed":        true,
+	"selected":        true,
+}
+
+// Element represents an HTML element.
 type Element struct {
 	Attributes map[string]string
 	Children   []*Element
@@ -16,6 +50,22 @@ type Element struct {
 	Text       string
 }
 
+// Text returns a new element with an end tag that contains text, e.g.
+// Text("span", "foo") for “<span>foo</span>”. text is escaped when the
+// element is rendered.
+func Text(tagName, text string) *Element {
+	return &Element{
+		HasEndTag: true,
+		TagName:   tagName,
+		Text:      text,
+	}
+}
+
+// Void returns a new element without end tag, e.g. Void("br") for “<br>”.
+func Void(tagName string) *Element {
+	return &Element{TagName: tagName}
+}
+
 // AddAttributeValue appends value to the named attribute’s value, separated by
 // a space. If the attribute does not exist, it is created first. This method is
 // chainable.
@@ -34,6 +84,43 @@ func (e *Element) AddAttributeValue(name, value string) *Element {
 	return e
 }
 
+// Append appends children to the element’s children. This method is
+// chainable.
+func (e *Element) Append(children ...*Element) *Element {
+	if e == nil {
+		return nil
+	}
+
+	e.Children = append(e.Children, children...)
+	return e
+}
+
+// Clone returns a deep copy of the element, including its attributes and
+// children.
+func (e *Element) Clone() *Element {
+	if e == nil {
+		return nil
+	}
+
+	clone := *e
+
+	if e.Attributes != nil {
+		clone.Attributes = make(map[string]string, len(e.Attributes))
+		for name, value := range e.Attributes {
+			clone.Attributes[name] = value
+		}
+	}
+
+	if e.Children != nil {
+		clone.Children = make([]*Element, len(e.Children))
+		for i, child := range e.Children {
+			clone.Children[i] = child.Clone()
+		}
+	}
+
+	return &clone
+}
+
 // SetAttributeValue replaces the value of the named attribute with the provided
 // one. If the attribute does not exist, it is created first. This method is
 // chainable.
@@ -59,33 +146,14 @@ func (e *Element) String() string {
 		return ""
 	}
 
-	capacity := 2 + len(e.Children)
-
-	if len(e.Attributes) > 0 {
-		capacity += 1
-	}
+	capacity := 1 + len(e.Children)
 
 	if e.HasEndTag {
 		capacity += 1
 	}
 
 	pieces := make([]string, 0, capacity)
-	pieces = append(pieces, "<"+e.TagName)
-
-	if len(e.Attributes) > 0 {
-		attributes := make(sort.StringSlice, 0, len(e.Attributes))
-		for k, v := range e.Attributes {
-			if v == "" {
-				attributes = append(attributes, k)
-			} else {
-				attributes = append(attributes, k+`="`+html.EscapeString(v)+`"`)
-			}
-		}
-		sort.Sort(attributes)
-		pieces = append(pieces, " "+strings.Join(attributes, " "))
-	}
-
-	pieces = append(pieces, ">")
+	pieces = append(pieces, e.startTag())
 
 	for _, child := range e.Children {
 		pieces = append(pieces, child.String())
@@ -97,3 +165,55 @@ func (e *Element) String() string {
 
 	return strings.Join(pieces, "")
 }
+
+// StringIndent is like String, but puts each child element on a new line,
+// indented by one more copy of indent than its parent. Elements without
+// children are rendered on a single line.
+func (e *Element) StringIndent(indent string) string {
+	return strings.Join(e.indentedLines(indent, ""), "\n")
+}
+
+// indentedLines returns the lines of StringIndent, each prefixed with prefix.
+func (e *Element) indentedLines(indent, prefix string) []string {
+	if e == nil || e.TagName == "" {
+		return nil
+	}
+
+	if len(e.Children) == 0 {
+		return []string{prefix + e.String()}
+	}
+
+	lines := []string{prefix + e.startTag()}
+
+	for _, child := range e.Children {
+		lines = append(lines, child.indentedLines(indent, prefix+indent)...)
+	}
+
+	if e.HasEndTag {
+		if e.Text != "" {
+			lines = append(lines, prefix+indent+html.EscapeString(e.Text))
+		}
+		lines = append(lines, prefix+"</"+e.TagName+">")
+	}
+
+	return lines
+}
+
+// startTag returns the element’s start tag, including its attributes.
+func (e *Element) startTag() string {
+	if len(e.Attributes) == 0 {
+		return "<" + e.TagName + ">"
+	}
+
+	attributes := make(sort.StringSlice, 0, len(e.Attributes))
+	for k, v := range e.Attributes {
+		if v == "" && BooleanAttributes[k] {
+			attributes = append(attributes, k)
+		} else {
+			attributes = append(attributes, k+`="`+html.EscapeString(v)+`"`)
+		}
+	}
+	sort.Sort(attributes)
+
+	return "<" + e.TagName + " " + strings.Join(attributes, " ") + ">"
+}
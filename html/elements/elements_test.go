@@ -66,6 +66,53 @@ func TestElement_AddAttributeValue(t *testing.T) {
 	}
 }
 
+func TestElement_Append(t *testing.T) {
+	var nilElement *Element
+	if result := nilElement.Append(Void("br")); result != nil {
+		t.Errorf("Expected nil, got %+v", result)
+	}
+
+	element := Text("p", "a < b").
+		SetAttributeValue("class", "note").
+		Append(Text("strong", `"foo" & 'bar'`), Void("br")).
+		Append(Text("em", "<script>"))
+
+	expected := `<p class="note"><strong>&#34;foo&#34; &amp; &#39;bar&#39;</strong><br><em>&lt;script&gt;</em>a &lt; b</p>`
+	if result := element.String(); result != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, result)
+	}
+}
+
+func TestElement_Clone(t *testing.T) {
+	var nilElement *Element
+	if clone := nilElement.Clone(); clone != nil {
+		t.Errorf("Expected nil, got %+v", clone)
+	}
+
+	element := &Element{
+		Attributes: map[string]string{"class": "a"},
+		Children: []*Element{
+			{Attributes: map[string]string{"id": "b"}, TagName: "span"},
+		},
+		HasEndTag: true,
+		TagName:   "div",
+		Text:      "foo",
+	}
+
+	clone := element.Clone()
+	if !reflect.DeepEqual(clone, element) {
+		t.Fatalf("Expected %+v, got %+v", element, clone)
+	}
+
+	clone.Attributes["class"] = "c"
+	clone.Children[0].Attributes["id"] = "d"
+	clone.Children = append(clone.Children, &Element{TagName: "br"})
+
+	if element.Attributes["class"] != "a" || element.Children[0].Attributes["id"] != "b" || len(element.Children) != 1 {
+		t.Errorf("Expected original element to be unchanged, got %+v", element)
+	}
+}
+
 func TestElement_SetAttributeValue(t *testing.T) {
 	tests := []struct {
 		element    *Element
@@ -279,7 +326,7 @@ func TestElement_String(t *testing.T) {
 				TagName:   "foo",
 				Text:      "",
 			},
-			expected: `<foo a="Attribute a value" b c="Attribute c value" d="Attribute d value &lt; &gt; &amp; &#39; &#34;">`,
+			expected: `<foo a="Attribute a value" b="" c="Attribute c value" d="Attribute d value &lt; &gt; &amp; &#39; &#34;">`,
 		},
 		{
 			element: &Element{
@@ -293,7 +340,7 @@ func TestElement_String(t *testing.T) {
 				TagName:   "foo",
 				Text:      `Five special HTML characters < > & ' "`,
 			},
-			expected: `<foo a="Attribute a value" b c="Attribute c value" d="Attribute d value &lt; &gt; &amp; &#39; &#34;">`,
+			expected: `<foo a="Attribute a value" b="" c="Attribute c value" d="Attribute d value &lt; &gt; &amp; &#39; &#34;">`,
 		},
 		{
 			element: &Element{
@@ -307,7 +354,7 @@ func TestElement_String(t *testing.T) {
 				TagName:   "foo",
 				Text:      "",
 			},
-			expected: `<foo a="Attribute a value" b c="Attribute c value" d="Attribute d value &lt; &gt; &amp; &#39; &#34;"></foo>`,
+			expected: `<foo a="Attribute a value" b="" c="Attribute c value" d="Attribute d value &lt; &gt; &amp; &#39; &#34;"></foo>`,
 		},
 		{
 			element: &Element{
@@ -321,7 +368,18 @@ func TestElement_String(t *testing.T) {
 				TagName:   "foo",
 				Text:      `Five special HTML characters < > & ' "`,
 			},
-			expected: `<foo a="Attribute a value" b c="Attribute c value" d="Attribute d value &lt; &gt; &amp; &#39; &#34;">Five special HTML characters &lt; &gt; &amp; &#39; &#34;</foo>`,
+			expected: `<foo a="Attribute a value" b="" c="Attribute c value" d="Attribute d value &lt; &gt; &amp; &#39; &#34;">Five special HTML characters &lt; &gt; &amp; &#39; &#34;</foo>`,
+		},
+		{
+			element: &Element{
+				Attributes: map[string]string{
+					"required": "",
+					"type":     "text",
+					"value":    "",
+				},
+				TagName: "input",
+			},
+			expected: `<input required type="text" value="">`,
 		},
 	}
 
@@ -331,3 +389,50 @@ func TestElement_String(t *testing.T) {
 		}
 	}
 }
+
+func TestElement_StringIndent(t *testing.T) {
+	tests := []struct {
+		element  *Element
+		expected string
+	}{
+		{
+			element:  nil,
+			expected: "",
+		},
+		{
+			element:  &Element{HasEndTag: true, TagName: "p", Text: "foo"},
+			expected: "<p>foo</p>",
+		},
+		{
+			element: &Element{
+				Attributes: map[string]string{"class": "a"},
+				Children: []*Element{
+					{HasEndTag: true, TagName: "label", Text: "Name"},
+					{Attributes: map[string]string{"name": "name"}, TagName: "input"},
+					{
+						Children:  []*Element{{HasEndTag: true, TagName: "b", Text: "x"}},
+						HasEndTag: true,
+						TagName:   "span",
+						Text:      "y",
+					},
+				},
+				HasEndTag: true,
+				TagName:   "div",
+			},
+			expected: "<div class=\"a\">\n" +
+				"  <label>Name</label>\n" +
+				"  <input name=\"name\">\n" +
+				"  <span>\n" +
+				"    <b>x</b>\n" +
+				"    y\n" +
+				"  </span>\n" +
+				"</div>",
+		},
+	}
+
+	for i, test := range tests {
+		if result := test.element.StringIndent("  "); result != test.expected {
+			t.Errorf("Test %d returned\n%s\nexpected\n%s", i+1, result, test.expected)
+		}
+	}
+}
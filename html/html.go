@@ -1,4 +1,5 @@
-// Package html removes whitespace and comments from HTML code.
+// Package html removes whitespace and comments from HTML code, and secures
+// links to external sites.
 package html
 
 import (
@@ -9,22 +10,15 @@ import (
 )
 
 var (
-	regExpHtmlComment                   = regexp.MustCompile("<!--(.|[\r\n])*?-->")
-	regExpLineBreak                     = regexp.MustCompile("[\r\n]+")
-	regExpParagraphDelimiter            = regexp.MustCompile("(\r\n){2,}")
-	regExpTag                           = regexp.MustCompile("<(.|[\r\n])*?>")
-	regExpWhitespaceAtStart             = regexp.MustCompile("^[ \f\n\r\t\v]+")
-	regExpWhitespaceAtEnd               = regexp.MustCompile("[ \f\n\r\t\v]+$")
-	regExpWhitespaceBetweenTags         = regexp.MustCompile(">[ \f\n\r\t\v]+<")
-	regExpWhitespaceBetweenActions      = regexp.MustCompile("}}[ \f\n\r\t\v]+{{")
-	regExpWhitespaceBetweenTagAndAction = regexp.MustCompile(">[ \f\n\r\t\v]+{{")
-	regExpWhitespaceBetweenActionAndTag = regexp.MustCompile("}}[ \f\n\r\t\v]+<")
-	regExpWhitespaceInsideTagStart      = regexp.MustCompile("<(/)?[ \f\n\r\t\v]+")
-	regExpWhitespaceInsideTagEnd        = regexp.MustCompile("[ \f\n\r\t\v]+(/?)>")
-	regExpWhitespaceInsideTagEqualSign  = regexp.MustCompile("[ \f\n\r\t\v]*=[ \f\n\r\t\v]*")
-	regExpWhitespaceInsideTag           = regexp.MustCompile("[ \f\n\r\t\v]{2,}")
+	regExpConditionalComment = regexp.MustCompile(`^<!--(\[if\s[^\]]*\]>|<!\[endif\])`)
+	regExpHtmlComment        = regexp.MustCompile("<!--(.|[\r\n])*?-->")
+	regExpParagraphDelimiter = regexp.MustCompile("(\r\n){2,}")
 )
 
+// StripComments determines whether RemoveWhitespace also removes HTML
+// comments. Conditional comments are kept, see RemoveCommentsExceptConditional.
+var StripComments = false
+
 // Paragraphs takes a plain text string, replaces single line breaks by <br>,
 // and wraps <p></p> tags around text blocks that are separated by two or more
 // line breaks.
@@ -41,26 +35,163 @@ func RemoveComments(html []byte) []byte {
 	return regExpHtmlComment.ReplaceAll(html, []byte(""))
 }
 
+// RemoveCommentsExceptConditional removes HTML comments like RemoveComments,
+// but keeps conditional comments, e.g. “<!--[if lt IE 9]>…<![endif]-->”, and
+// the comments that enclose downlevel-revealed conditional content, e.g.
+// “<!--[if !IE]><!-->” and “<!--<![endif]-->”.
+func RemoveCommentsExceptConditional(html []byte) []byte {
+	return regExpHtmlComment.ReplaceAllFunc(html, func(comment []byte) []byte {
+		if regExpConditionalComment.Match(comment) {
+			return comment
+		}
+		return nil
+	})
+}
+
 // RemoveWhitespace removes whitespace between tags, actions, and at the
-// beginning and end of the HTML code.
+// beginning and end of the HTML code. Inside tags, line breaks are replaced by
+// spaces, and whitespace after the tag’s opening bracket, before its closing
+// bracket, around equal signs and between actions is removed. Other runs of
+// whitespace inside tags are collapsed to a single space. The HTML code is
+// scanned once. Each tag is cleaned with several passes over the tag only,
+// using buffers that are reused between tags. If StripComments is true,
+// comments are removed first with RemoveCommentsExceptConditional.
 func RemoveWhitespace(html []byte) []byte {
-	html = regExpWhitespaceBetweenTags.ReplaceAll(html, []byte("><"))
-	html = regExpWhitespaceBetweenActions.ReplaceAll(html, []byte("}}{{"))
-	html = regExpWhitespaceBetweenTagAndAction.ReplaceAll(html, []byte(">{{"))
-	html = regExpWhitespaceBetweenActionAndTag.ReplaceAll(html, []byte("}}<"))
-	html = regExpWhitespaceAtStart.ReplaceAll(html, []byte(""))
-	html = regExpWhitespaceAtEnd.ReplaceAll(html, []byte(""))
-
-	tags := regExpTag.FindAll(html, -1)
-
-	for _, tag := range tags {
-		cleanTag := regExpLineBreak.ReplaceAll(tag, []byte(" "))
-		cleanTag = regExpWhitespaceInsideTagStart.ReplaceAll(cleanTag, []byte("<$1"))
-		cleanTag = regExpWhitespaceInsideTagEnd.ReplaceAll(cleanTag, []byte("$1>"))
-		cleanTag = regExpWhitespaceInsideTagEqualSign.ReplaceAllLiteral(cleanTag, []byte("="))
-		cleanTag = regExpWhitespaceInsideTag.ReplaceAllLiteral(cleanTag, []byte(" "))
-		html = bytes.Replace(html, tag, cleanTag, 1)
+	if StripComments {
+		html = RemoveCommentsExceptConditional(html)
+	}
+
+	result := make([]byte, 0, len(html))
+	cleaner := &tagCleaner{}
+	hasTagEnd := true
+
+	for i := 0; i < len(html); {
+		if html[i] == '<' && hasTagEnd {
+			if end := bytes.IndexByte(html[i:], '>'); end != -1 {
+				result = cleaner.clean(result, html[i:i+end+1])
+				i += end + 1
+				continue
+			}
+			hasTagEnd = false
+		}
+
+		if !isWhitespace(html[i]) {
+			result = append(result, html[i])
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(html) && isWhitespace(html[j]) {
+			j++
+		}
+
+		if i > 0 && j < len(html) {
+			result = append(result, removeBetweenTagsAndActions(html[:i], html[i:j], html[j:])...)
+		}
+		i = j
+	}
+
+	return result
+}
+
+// tagCleaner removes superfluous whitespace from tags. Its buffers are reused
+// for all tags of a document.
+type tagCleaner struct {
+	buffer1, buffer2 []byte
+}
+
+// clean appends tag to dst after removing superfluous whitespace from it. tag
+// must start with “<” and end with “>”, and must not contain another “>”.
+func (c *tagCleaner) clean(dst, tag []byte) []byte {
+	c.buffer1 = mapWhitespace(c.buffer1[:0], tag, isWhitespace, removeBetweenTagsAndActions)
+	c.buffer2 = mapWhitespace(c.buffer2[:0], c.buffer1, isLineBreak, replaceWithSpace)
+	c.buffer1 = mapWhitespace(c.buffer1[:0], c.buffer2, isWhitespace, removeAfterTagStart)
+	c.buffer2 = mapWhitespace(c.buffer2[:0], c.buffer1, isWhitespace, removeBeforeTagEnd)
+	c.buffer1 = mapWhitespace(c.buffer1[:0], c.buffer2, isWhitespace, removeAroundEqualSign)
+	return mapWhitespace(dst, c.buffer1, isWhitespace, collapse)
+}
+
+// mapWhitespace appends src to dst, replacing each run of bytes for which
+// isSpace returns true by the result of fn. fn receives the bytes before the
+// run, the run itself, and the bytes after the run.
+func mapWhitespace(dst, src []byte, isSpace func(byte) bool, fn func(before, run, after []byte) []byte) []byte {
+	for i := 0; i < len(src); {
+		if !isSpace(src[i]) {
+			dst = append(dst, src[i])
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(src) && isSpace(src[j]) {
+			j++
+		}
+		dst = append(dst, fn(src[:i], src[i:j], src[j:])...)
+		i = j
+	}
+	return dst
+}
+
+var space = []byte(" ")
+
+// removeBetweenTagsAndActions removes run if it is preceded by a tag or action
+// and followed by a tag or action.
+func removeBetweenTagsAndActions(before, run, after []byte) []byte {
+	if (bytes.HasSuffix(before, []byte(">")) || bytes.HasSuffix(before, []byte("}}"))) &&
+		(bytes.HasPrefix(after, []byte("<")) || bytes.HasPrefix(after, []byte("{{"))) {
+		return nil
+	}
+	return run
+}
+
+// replaceWithSpace replaces run by a single space.
+func replaceWithSpace(before, run, after []byte) []byte {
+	return space
+}
+
+// removeAfterTagStart removes run if it follows “<” or “</”.
+func removeAfterTagStart(before, run, after []byte) []byte {
+	if bytes.HasSuffix(before, []byte("<")) || bytes.HasSuffix(before, []byte("</")) {
+		return nil
+	}
+	return run
+}
+
+// removeBeforeTagEnd removes run if it precedes “>” or “/>”.
+func removeBeforeTagEnd(before, run, after []byte) []byte {
+	if bytes.HasPrefix(after, []byte(">")) || bytes.HasPrefix(after, []byte("/>")) {
+		return nil
 	}
+	return run
+}
+
+// removeAroundEqualSign removes run if it precedes or follows “=”.
+func removeAroundEqualSign(before, run, after []byte) []byte {
+	if bytes.HasSuffix(before, []byte("=")) || bytes.HasPrefix(after, []byte("=")) {
+		return nil
+	}
+	return run
+}
+
+// collapse replaces run by a single space if it is longer than one byte.
+func collapse(before, run, after []byte) []byte {
+	if len(run) > 1 {
+		return space
+	}
+	return run
+}
 
-	return html
+// isLineBreak returns whether b is a line break character.
+func isLineBreak(b byte) bool {
+	return b == '\n' || b == '\r'
+}
+
+// isWhitespace returns whether b is a whitespace character.
+func isWhitespace(b byte) bool {
+	switch b {
+	case ' ', '\f', '\n', '\r', '\t', '\v':
+		return true
+	}
+	return false
 }
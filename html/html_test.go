@@ -1,7 +1,10 @@
 package html
 
 import (
+	"bytes"
 	"html/template"
+	"math/rand"
+	"regexp"
 	"testing"
 )
 
@@ -101,6 +104,36 @@ func TestRemoveComments(t *testing.T) {
 	}
 }
 
+func TestRemoveCommentsExceptConditional(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"foo<!-- Comment \n-->bar", "foobar"},
+		{"<!--[if lt IE 9]><script src=\"a.js\"></script><![endif]-->", "<!--[if lt IE 9]><script src=\"a.js\"></script><![endif]-->"},
+		{"<!--[if !IE]><!--><p>a</p><!-- b --><!--<![endif]-->", "<!--[if !IE]><!--><p>a</p><!--<![endif]-->"},
+		{"<!-- [if] -->foo<!--if IE-->", "foo"},
+	}
+
+	for i, test := range tests {
+		if result := RemoveCommentsExceptConditional([]byte(test.input)); string(result) != test.expected {
+			t.Errorf("%d. Expected %q, got %q.", i, test.expected, result)
+		}
+	}
+}
+
+func TestRemoveWhitespace_StripComments(t *testing.T) {
+	StripComments = true
+	defer func() { StripComments = false }()
+
+	input := []byte("<p>\n  <!-- Comment -->\n  <!--[if IE]><b>IE</b><![endif]-->\n</p>")
+	expected := "<p><!--[if IE]><b>IE</b><![endif]--></p>"
+
+	if result := RemoveWhitespace(input); string(result) != expected {
+		t.Errorf("Expected %q, got %q.", expected, result)
+	}
+}
+
 func TestRemoveWhitespace(t *testing.T) {
 	expectedResult := []byte(`<!DOCTYPE html><html><head><meta charset="utf-8"><meta name="viewport" content="initial-scale=1, width=device-width"><title>Panoptikos</title>{{if .IsDevAppServer}}{{range .DevCssFiles}}<link href="{{.}}" rel="stylesheet" type="text/css">{{end}}{{else}}<link href="/{{.CompiledCssFile}}" rel="stylesheet" type="text/css">{{end}}</head><body><p id="some-class">Foo</p><p id="some-other-class">Bar</p>{{if .IsDevAppServer}}{{range .DevJsFiles}}<script src="{{.}}"></script>{{end}}{{else}}<script src="/{{.CompiledJsFile}}"></script>{{end}}<div foo bar="baz" baz1 baz2 baz3></div><br><!-- Comment 1 --><script>var s = "Some JavaScript code"</script><!-- Comment 2 --><noscript><div>Enable JavaScript.</div></noscript></body></html>`)
 
@@ -119,8 +152,103 @@ func TestRemoveWhitespace(t *testing.T) {
 	}
 }
 
+// Regular expressions of removeWhitespaceRegExp.
+var (
+	regExpLineBreak                     = regexp.MustCompile("[\r\n]+")
+	regExpTag                           = regexp.MustCompile("<(.|[\r\n])*?>")
+	regExpWhitespaceAtStart             = regexp.MustCompile("^[ \f\n\r\t\v]+")
+	regExpWhitespaceAtEnd               = regexp.MustCompile("[ \f\n\r\t\v]+$")
+	regExpWhitespaceBetweenTags         = regexp.MustCompile(">[ \f\n\r\t\v]+<")
+	regExpWhitespaceBetweenActions      = regexp.MustCompile("}}[ \f\n\r\t\v]+{{")
+	regExpWhitespaceBetweenTagAndAction = regexp.MustCompile(">[ \f\n\r\t\v]+{{")
+	regExpWhitespaceBetweenActionAndTag = regexp.MustCompile("}}[ \f\n\r\t\v]+<")
+	regExpWhitespaceInsideTagStart      = regexp.MustCompile("<(/)?[ \f\n\r\t\v]+")
+	regExpWhitespaceInsideTagEnd        = regexp.MustCompile("[ \f\n\r\t\v]+(/?)>")
+	regExpWhitespaceInsideTagEqualSign  = regexp.MustCompile("[ \f\n\r\t\v]*=[ \f\n\r\t\v]*")
+	regExpWhitespaceInsideTag           = regexp.MustCompile("[ \f\n\r\t\v]{2,}")
+)
+
+// removeWhitespaceRegExp is the former, regular expression based
+// implementation of RemoveWhitespace. It serves as reference for testing and
+// benchmarking.
+func removeWhitespaceRegExp(html []byte) []byte {
+	html = regExpWhitespaceBetweenTags.ReplaceAll(html, []byte("><"))
+	html = regExpWhitespaceBetweenActions.ReplaceAll(html, []byte("}}{{"))
+	html = regExpWhitespaceBetweenTagAndAction.ReplaceAll(html, []byte(">{{"))
+	html = regExpWhitespaceBetweenActionAndTag.ReplaceAll(html, []byte("}}<"))
+	html = regExpWhitespaceAtStart.ReplaceAll(html, []byte(""))
+	html = regExpWhitespaceAtEnd.ReplaceAll(html, []byte(""))
+
+	tags := regExpTag.FindAll(html, -1)
+
+	for _, tag := range tags {
+		cleanTag := regExpLineBreak.ReplaceAll(tag, []byte(" "))
+		cleanTag = regExpWhitespaceInsideTagStart.ReplaceAll(cleanTag, []byte("<$1"))
+		cleanTag = regExpWhitespaceInsideTagEnd.ReplaceAll(cleanTag, []byte("$1>"))
+		cleanTag = regExpWhitespaceInsideTagEqualSign.ReplaceAllLiteral(cleanTag, []byte("="))
+		cleanTag = regExpWhitespaceInsideTag.ReplaceAllLiteral(cleanTag, []byte(" "))
+		html = bytes.Replace(html, tag, cleanTag, 1)
+	}
+
+	return html
+}
+
+func TestRemoveWhitespace_matchesRegExp(t *testing.T) {
+	alphabet := []byte("<>{}=/ab \f\n\r\t\v")
+	random := rand.New(rand.NewSource(1))
+
+	inputs := [][]byte{html}
+	for i := 0; i < 20000; i++ {
+		input := make([]byte, random.Intn(30))
+		for j := range input {
+			input[j] = alphabet[random.Intn(len(alphabet))]
+		}
+		inputs = append(inputs, input)
+	}
+
+	for _, input := range inputs {
+		expected := removeWhitespaceRegExp(input)
+		if result := RemoveWhitespace(input); !bytes.Equal(result, expected) {
+			t.Fatalf("RemoveWhitespace(%q) returned %q, expected %q", input, result, expected)
+		}
+	}
+}
+
+func TestSecureExternalLinks(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`<a href="/about">About</a>`, `<a href="/about">About</a>`},
+		{`<a href="https://example.com/about">About</a>`, `<a href="https://example.com/about">About</a>`},
+		{`<a href="https://EXAMPLE.com:8080/">Home</a>`, `<a href="https://EXAMPLE.com:8080/">Home</a>`},
+		{`<a>Anchor</a>`, `<a>Anchor</a>`},
+		{`<abbr title="https://other.com">O</abbr>`, `<abbr title="https://other.com">O</abbr>`},
+		{`<a href="https://other.com">Other</a>`, `<a href="https://other.com" rel="noopener noreferrer">Other</a>`},
+		{`<A HREF='//other.com' >Other</A>`, `<A HREF='//other.com' rel="noopener noreferrer">Other</A>`},
+		{`<a href=/help target=_blank>Help</a>`, `<a href=/help target=_blank rel="noopener noreferrer">Help</a>`},
+		{`<a rel="nofollow" href="http://other.com">Other</a>`, `<a rel="nofollow noopener noreferrer" href="http://other.com">Other</a>`},
+		{`<a href="http://other.com" rel=NOOPENER>Other</a>`, `<a href="http://other.com" rel="NOOPENER noreferrer">Other</a>`},
+		{`<p><a href="http://a.com">A</a> <a href="/b">B</a></p>`, `<p><a href="http://a.com" rel="noopener noreferrer">A</a> <a href="/b">B</a></p>`},
+		{`<a title='a>b' href="http://other.com">Other</a>`, `<a title='a>b' href="http://other.com" rel="noopener noreferrer">Other</a>`},
+		{`<a href="http://other.com" rel='x"onmouseover="alert(1)'>Other</a>`, `<a href="http://other.com" rel="x&#34;onmouseover=&#34;alert(1) noopener noreferrer">Other</a>`},
+	}
+
+	for _, test := range tests {
+		if result := SecureExternalLinks([]byte(test.input), "example.com"); string(result) != test.expected {
+			t.Errorf("SecureExternalLinks(%q) returned\n%s\nexpected\n%s", test.input, result, test.expected)
+		}
+	}
+}
+
 func BenchmarkRemoveWhitespace(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		RemoveWhitespace(html)
 	}
 }
+
+func BenchmarkRemoveWhitespaceRegExp(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		removeWhitespaceRegExp(html)
+	}
+}
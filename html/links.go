@@ -0,0 +1,95 @@
+package html
+
+import (
+	"html/template"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	regExpAnchorTag = regexp.MustCompile(`(?i)<a(\s(?:[^"'>]|"[^"]*"|'[^']*')*)?>`)
+	regExpAttribute = regexp.MustCompile(`([^\s"'<>/=]+)(?:\s*=\s*("[^"]*"|'[^']*'|[^\s"'>]+))?`)
+)
+
+// secureRelValues are the rel values added by SecureExternalLinks.
+var secureRelValues = []string{"noopener", "noreferrer"}
+
+// SecureExternalLinks adds “noopener” and “noreferrer” to the rel attribute of
+// links that open in a new window (target="_blank") or point to a host other
+// than siteHost, e.g. “example.com”. This prevents the linked pages from
+// accessing the window that opened them (“reverse tabnabbing”), which is
+// useful for user-generated content. Existing rel values are kept. Links with
+// relative URLs point to siteHost.
+func SecureExternalLinks(html []byte, siteHost string) []byte {
+	return regExpAnchorTag.ReplaceAllFunc(html, func(tag []byte) []byte {
+		attributes := string(tag[2 : len(tag)-1])
+
+		var href, rel, target string
+		relStart, relEnd := -1, -1
+
+		for _, m := range regExpAttribute.FindAllStringSubmatchIndex(attributes, -1) {
+			name := strings.ToLower(attributes[m[2]:m[3]])
+			value := ""
+			if m[4] >= 0 {
+				value = unquote(attributes[m[4]:m[5]])
+			}
+
+			switch name {
+			case "href":
+				href = value
+			case "rel":
+				rel, relStart, relEnd = value, m[0], m[1]
+			case "target":
+				target = value
+			}
+		}
+
+		if !strings.EqualFold(target, "_blank") && !isExternalURL(href, siteHost) {
+			return tag
+		}
+
+		relValues := strings.Fields(rel)
+		for _, secureValue := range secureRelValues {
+			if !containsFold(relValues, secureValue) {
+				relValues = append(relValues, secureValue)
+			}
+		}
+		relAttribute := `rel="` + template.HTMLEscapeString(strings.Join(relValues, " ")) + `"`
+
+		if relStart >= 0 {
+			attributes = attributes[:relStart] + relAttribute + attributes[relEnd:]
+		} else {
+			attributes = strings.TrimRight(attributes, " \t\r\n") + " " + relAttribute
+		}
+		return []byte(string(tag[:2]) + attributes + ">")
+	})
+}
+
+// isExternalURL returns whether rawURL is an absolute URL whose host is not
+// siteHost.
+func isExternalURL(rawURL, siteHost string) bool {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return !strings.EqualFold(u.Hostname(), siteHost) && !strings.EqualFold(u.Host, siteHost)
+}
+
+// unquote removes the quotes around an attribute value, if it has any.
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// containsFold returns whether values contains value, ignoring case.
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package languages
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Bundle is a set of languages with a default language. It is the entry point
+// for programs that serve multiple languages.
+type Bundle struct {
+	// Default is the language returned by Match if no language in the bundle
+	// matches.
+	Default *Language
+
+	// Languages by lowercased language code.
+	languages map[string]*Language
+}
+
+// NewBundle returns a new instance of Bundle. defaultLanguage is added to the
+// bundle and used as its default language.
+func NewBundle(defaultLanguage *Language) *Bundle {
+	b := &Bundle{
+		Default:   defaultLanguage,
+		languages: make(map[string]*Language),
+	}
+	if defaultLanguage != nil {
+		b.Add(defaultLanguage)
+	}
+	return b
+}
+
+// Add adds language to the bundle. If a language with the same code already
+// exists, it is replaced.
+func (b *Bundle) Add(language *Language) {
+	b.languages[strings.ToLower(language.Code)] = language
+}
+
+// Get retrieves the language with the provided code. Codes are compared
+// case-insensitively. If the language cannot be found, nil is returned.
+func (b *Bundle) Get(code string) *Language {
+	return b.languages[strings.ToLower(code)]
+}
+
+// Match returns the language that best matches header, the value of an
+// “Accept-Language” HTTP header, e.g. “de-CH, de;q=0.9, en;q=0.8”. Language
+// ranges are tried in order of their quality value. A range matches a language
+// if their codes are equal, or if the range without its region, e.g. “de” for
+// “de-CH”, equals the language’s code. If no language matches, the default
+// language is returned.
+func (b *Bundle) Match(header string) *Language {
+	for _, code := range parseAcceptLanguage(header) {
+		if code == "*" {
+			break
+		}
+		if language := b.Get(code); language != nil {
+			return language
+		}
+		if i := strings.Index(code, "-"); i > 0 {
+			if language := b.Get(code[:i]); language != nil {
+				return language
+			}
+		}
+	}
+	return b.Default
+}
+
+// parseAcceptLanguage returns the language ranges in header, ordered by
+// descending quality value. Ranges with a quality value of 0 are omitted.
+func parseAcceptLanguage(header string) []string {
+	type languageRange struct {
+		code    string
+		quality float64
+	}
+
+	var ranges []languageRange
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		code := strings.TrimSpace(fields[0])
+		if code == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, field := range fields[1:] {
+			field = strings.TrimSpace(field)
+			if !strings.HasPrefix(field, "q=") {
+				continue
+			}
+			if q, err := strconv.ParseFloat(field[2:], 64); err == nil {
+				quality = q
+			}
+		}
+
+		if quality > 0 {
+			ranges = append(ranges, languageRange{code: code, quality: quality})
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	codes := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		codes = append(codes, r.code)
+	}
+	return codes
+}
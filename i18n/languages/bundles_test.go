@@ -0,0 +1,64 @@
+package languages_test
+
+import (
+	"testing"
+
+	"github.com/ChristianSiegert/go-packages/i18n/languages"
+)
+
+func TestBundle_Get(t *testing.T) {
+	en := languages.NewLanguage("en", "English")
+	enUS := languages.NewLanguage("en-US", "English (US)")
+
+	bundle := languages.NewBundle(en)
+	bundle.Add(enUS)
+
+	tests := []struct {
+		code     string
+		expected *languages.Language
+	}{
+		{"en", en},
+		{"en-US", enUS},
+		{"en-us", enUS},
+		{"de", nil},
+	}
+
+	for i, test := range tests {
+		if result := bundle.Get(test.code); result != test.expected {
+			t.Errorf("%d. Expected %v, got %v.", i, test.expected, result)
+		}
+	}
+}
+
+func TestBundle_Match(t *testing.T) {
+	de := languages.NewLanguage("de", "German")
+	en := languages.NewLanguage("en", "English")
+	enGB := languages.NewLanguage("en-GB", "English (UK)")
+
+	bundle := languages.NewBundle(en)
+	bundle.Add(de)
+	bundle.Add(enGB)
+
+	tests := []struct {
+		header   string
+		expected *languages.Language
+	}{
+		{"", en},
+		{"de", de},
+		{"DE", de},
+		{"de-CH", de},
+		{"en-GB", enGB},
+		{"en-US", en},
+		{"fr, de;q=0.5", de},
+		{"en;q=0.5, de;q=0.8", de},
+		{"de;q=0, en-GB", enGB},
+		{"fr, *", en},
+		{"fr", en},
+	}
+
+	for i, test := range tests {
+		if result := bundle.Match(test.header); result != test.expected {
+			t.Errorf("%d. Expected %v for %q, got %v.", i, test.expected.Code, test.header, result.Code)
+		}
+	}
+}
@@ -8,7 +8,10 @@ package languages
 import (
 	"bytes"
 	"fmt"
+	htmltemplate "html/template"
+	"io"
 	"log"
+	"sort"
 	"text/template"
 )
 
@@ -80,6 +83,33 @@ func (l *Language) Get(translationID string) *Translation {
 	return l.Translations[translationID]
 }
 
+// IDs returns the sorted IDs of the translations defined by the language.
+// Translations of fallback languages are not included.
+func (l *Language) IDs() []string {
+	ids := make([]string, 0, len(l.Translations))
+	for translationID := range l.Translations {
+		ids = append(ids, translationID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// MissingIn reports which translations of reference are missing from others.
+// The returned map contains the code of each language that misses
+// translations, and the sorted IDs of the missing translations. Translations
+// of fallback languages are not taken into account.
+func MissingIn(reference *Language, others ...*Language) map[string][]string {
+	missing := make(map[string][]string)
+	for _, translationID := range reference.IDs() {
+		for _, other := range others {
+			if _, ok := other.Translations[translationID]; !ok {
+				missing[other.Code] = append(missing[other.Code], translationID)
+			}
+		}
+	}
+	return missing
+}
+
 // Remove removes translations from the language.
 func (l *Language) Remove(translationIDs ...string) {
 	for _, translationID := range translationIDs {
@@ -88,9 +118,10 @@ func (l *Language) Remove(translationIDs ...string) {
 }
 
 // T returns the translation associated with translationID. If the translation
-// is missing from l, l.Fallbacks will be checked. If the translation is still
-// missing, translationID is returned. Args is optional. The first item of args
-// is provided to the translation as data, additional items are ignored.
+// is missing from l, l.Fallbacks will be checked, followed by their own
+// fallbacks, and so on. If the translation is still missing, translationID is
+// returned. Args is optional. The first item of args is provided to the
+// translation as data, additional items are ignored.
 func (l *Language) T(translationID string, args ...map[string]interface{}) string {
 	var templateData map[string]interface{}
 
@@ -98,12 +129,60 @@ func (l *Language) T(translationID string, args ...map[string]interface{}) strin
 		templateData = args[0]
 	}
 
-	languages := make([]*Language, 0, 1+len(l.Fallbacks))
-	languages = append(languages, l)
-	languages = append(languages, l.Fallbacks...)
+	s, _ := l.translate(translationID, templateData, func(translation *Translation) *template.Template {
+		return translation.Other
+	}, executeText)
+	return s
+}
+
+// THTML is like T, but the translation is rendered as HTML. Markup contained
+// in the translation itself is trusted, whereas the data provided with args is
+// escaped automatically. This allows translations like
+// `Click <a href="{{.URL}}">here</a>` without escaping user input manually.
+func (l *Language) THTML(translationID string, args ...map[string]interface{}) htmltemplate.HTML {
+	var templateData map[string]interface{}
+
+	if len(args) > 0 {
+		templateData = args[0]
+	}
+
+	s, ok := l.translate(translationID, templateData, func(translation *Translation) *template.Template {
+		return translation.Other
+	}, executeHTML)
+
+	// translationID is not a translation, so its markup is not trusted.
+	if !ok {
+		return htmltemplate.HTML(htmltemplate.HTMLEscapeString(s))
+	}
+	return htmltemplate.HTML(s)
+}
+
+// TN is like T, but picks the translation’s plural group based on count. count
+// is provided to the translation as data under the key “Count”, in addition to
+// the data provided with args.
+func (l *Language) TN(translationID string, count int, args ...map[string]interface{}) string {
+	templateData := map[string]interface{}{}
 
+	if len(args) > 0 {
+		for key, value := range args[0] {
+			templateData[key] = value
+		}
+	}
+	templateData["Count"] = count
+
+	s, _ := l.translate(translationID, templateData, func(translation *Translation) *template.Template {
+		return translation.pluralGroup(count)
+	}, executeText)
+	return s
+}
+
+// translate executes the template picked by pick from the first translation
+// associated with translationID, checking the languages returned by lookupOrder.
+// If no translation is found or executing it fails, translationID is returned
+// and ok is false.
+func (l *Language) translate(translationID string, templateData map[string]interface{}, pick func(*Translation) *template.Template, execute func(io.Writer, *template.Template, interface{}) error) (s string, ok bool) {
 	// Find translation associated with translationID
-	for _, language := range languages {
+	for _, language := range l.lookupOrder() {
 		translation := language.Get(translationID)
 		if translation == nil {
 			continue
@@ -111,12 +190,45 @@ func (l *Language) T(translationID string, args ...map[string]interface{}) strin
 
 		var buf bytes.Buffer
 
-		// TODO: Pick plural group based on quantity.
-		if err := translation.Other.Execute(&buf, templateData); err != nil {
+		if err := execute(&buf, pick(translation), templateData); err != nil {
 			log.Printf("languages: executing template %q with data %#v for language %s %s failed: %s\n", translationID, templateData, l.Code, l.Name, err)
-			return translationID
+			return translationID, false
 		}
-		return buf.String()
+		return buf.String(), true
+	}
+	return translationID, false
+}
+
+// lookupOrder returns l followed by its fallback languages, including the
+// fallbacks of fallbacks. Languages are ordered breadth-first, i.e. all of l’s
+// fallbacks come before their own fallbacks. Each language is contained once,
+// so cyclic fallbacks are safe.
+func (l *Language) lookupOrder() []*Language {
+	languages := []*Language{l}
+	seen := map[*Language]bool{l: true}
+
+	for i := 0; i < len(languages); i++ {
+		for _, fallback := range languages[i].Fallbacks {
+			if fallback != nil && !seen[fallback] {
+				seen[fallback] = true
+				languages = append(languages, fallback)
+			}
+		}
+	}
+	return languages
+}
+
+// executeText executes tpl as text template.
+func executeText(writer io.Writer, tpl *template.Template, data interface{}) error {
+	return tpl.Execute(writer, data)
+}
+
+// executeHTML executes tpl as HTML template, so data is escaped according to
+// its context. The parse tree is copied, because escaping modifies it.
+func executeHTML(writer io.Writer, tpl *template.Template, data interface{}) error {
+	htmlTpl, err := htmltemplate.New(tpl.Name()).AddParseTree(tpl.Name(), tpl.Tree.Copy())
+	if err != nil {
+		return err
 	}
-	return translationID
+	return htmlTpl.Execute(writer, data)
 }
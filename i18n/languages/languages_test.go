@@ -2,6 +2,7 @@ package languages_test
 
 import (
 	"fmt"
+	htmltemplate "html/template"
 	"reflect"
 	"strconv"
 	"testing"
@@ -115,6 +116,34 @@ func TestLanguage_SetMulti(t *testing.T) {
 	}
 }
 
+func TestLanguage_IDs(t *testing.T) {
+	language := languages.NewLanguage("en", "English")
+	if ids := language.IDs(); len(ids) != 0 {
+		t.Errorf("Expected no IDs, got %v", ids)
+	}
+
+	language.SetMulti(map[string]interface{}{"c": "C", "a": "A", "b": "B"})
+	if expected, ids := []string{"a", "b", "c"}, language.IDs(); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected %v, got %v", expected, ids)
+	}
+}
+
+func TestMissingIn(t *testing.T) {
+	english := languages.NewLanguage("en", "English")
+	english.SetMulti(map[string]interface{}{"a": "A", "b": "B", "c": "C"})
+
+	german := languages.NewLanguage("de", "German")
+	german.SetMulti(map[string]interface{}{"a": "A", "d": "D"})
+
+	french := languages.NewLanguage("fr", "French")
+	french.SetMulti(map[string]interface{}{"a": "A", "b": "B", "c": "C"})
+
+	expected := map[string][]string{"de": {"b", "c"}}
+	if result := languages.MissingIn(english, german, french); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
 func TestLanguage_Remove(t *testing.T) {
 	type args struct {
 		translationIDs []string
@@ -204,6 +233,115 @@ func TestLanguage_T(t *testing.T) {
 	}
 }
 
+func TestLanguage_T_fallbackChain(t *testing.T) {
+	german := languages.NewLanguage("de", "German")
+	english := languages.NewLanguage("en", "English")
+	spanish := languages.NewLanguage("es", "Spanish")
+	french := languages.NewLanguage("fr", "French")
+
+	german.Fallbacks = []*languages.Language{english, french}
+	english.Fallbacks = []*languages.Language{spanish, german}
+	french.Fallbacks = []*languages.Language{german}
+
+	spanish.Set("farewell", "Adiós")
+	spanish.Set("greeting", "Hola")
+	french.Set("greeting", "Bonjour")
+
+	tests := []struct {
+		translationID string
+		want          string
+	}{
+		// Translation only exists at the deepest level.
+		{"farewell", "Adiós"},
+		// Direct fallbacks are checked before their fallbacks.
+		{"greeting", "Bonjour"},
+		// Cycles do not cause endless lookups.
+		{"missing", "missing"},
+	}
+
+	for _, test := range tests {
+		if got := german.T(test.translationID); got != test.want {
+			t.Errorf("T(%q): Expected %q, got %q.", test.translationID, test.want, got)
+		}
+	}
+}
+
+func TestLanguage_TN(t *testing.T) {
+	english := languages.NewLanguage("en", "English")
+	english.Set("comments", &languages.Translation{
+		Zero:  MustTemplate(t, "comments", "No comments"),
+		One:   MustTemplate(t, "comments", "{{.Count}} comment by {{.Name}}"),
+		Other: MustTemplate(t, "comments", "{{.Count}} comments by {{.Name}}"),
+	})
+
+	var tests = []struct {
+		count int
+		data  map[string]interface{}
+		want  string
+	}{
+		{0, nil, "No comments"},
+		{1, map[string]interface{}{"Name": "Christian"}, "1 comment by Christian"},
+		{2, map[string]interface{}{"Name": "Christian"}, "2 comments by Christian"},
+		{5, map[string]interface{}{"Name": "Christian"}, "5 comments by Christian"},
+	}
+
+	for i, test := range tests {
+		t.Run("Test "+strconv.Itoa(i), func(t *testing.T) {
+			if got := english.TN("comments", test.count, test.data); got != test.want {
+				t.Errorf("TN(%q, %d, %s): Expected %q, got %q.", "comments", test.count, test.data, test.want, got)
+			}
+		})
+	}
+}
+
+func TestLanguage_THTML(t *testing.T) {
+	english := languages.NewLanguage("en", "English")
+	english.Set("click", `Click <a href="{{.URL}}">{{.Label}}</a>`)
+	english.Set("<br>", `<br>`)
+
+	var tests = []struct {
+		translationID string
+		data          map[string]interface{}
+		want          htmltemplate.HTML
+	}{
+		{
+			"click",
+			map[string]interface{}{"URL": "/foo?a=1&b=2", "Label": "here"},
+			`Click <a href="/foo?a=1&amp;b=2">here</a>`,
+		},
+		{
+			"click",
+			map[string]interface{}{"URL": "javascript:alert(1)", "Label": "<b>here</b>"},
+			`Click <a href="#ZgotmplZ">&lt;b&gt;here&lt;/b&gt;</a>`,
+		},
+		{
+			"<missing>",
+			nil,
+			`&lt;missing&gt;`,
+		},
+		{
+			"<br>",
+			nil,
+			`<br>`,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run("Test "+strconv.Itoa(i), func(t *testing.T) {
+			// Executing twice must not alter the translation
+			english.THTML(test.translationID, test.data)
+
+			if got := english.THTML(test.translationID, test.data); got != test.want {
+				t.Errorf("THTML(%q, %s): Expected %q, got %q.", test.translationID, test.data, test.want, got)
+			}
+		})
+	}
+
+	if got, want := english.T("click", map[string]interface{}{"URL": "/", "Label": "<b>"}), `Click <a href="/"><b></a>`; got != want {
+		t.Errorf("T after THTML: Expected %q, got %q.", want, got)
+	}
+}
+
 func Example() {
 	language := languages.NewLanguage("de", "German")
 	language.Set("greeting", "Hallo")
@@ -0,0 +1,142 @@
+package languages
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// numberFormat describes how numbers are written in a language.
+type numberFormat struct {
+	// currencyAfter is true if the currency symbol follows the amount, e.g.
+	// “1.234,56 €”. Amount and symbol are separated by a no-break space.
+	currencyAfter bool
+
+	decimalSeparator string
+	groupSeparator   string
+}
+
+// numberFormats contains the number formats of languages, keyed by base
+// language code. Languages not listed use the English format.
+var numberFormats = map[string]numberFormat{
+	"de": {currencyAfter: true, decimalSeparator: ",", groupSeparator: "."},
+	"en": {decimalSeparator: ".", groupSeparator: ","},
+	"es": {currencyAfter: true, decimalSeparator: ",", groupSeparator: "."},
+	"fr": {currencyAfter: true, decimalSeparator: ",", groupSeparator: "\u00a0"},
+	"it": {currencyAfter: true, decimalSeparator: ",", groupSeparator: "."},
+	"ja": {decimalSeparator: ".", groupSeparator: ","},
+	"nl": {decimalSeparator: ",", groupSeparator: "."},
+	"pl": {currencyAfter: true, decimalSeparator: ",", groupSeparator: "\u00a0"},
+	"pt": {currencyAfter: true, decimalSeparator: ",", groupSeparator: "."},
+	"ru": {currencyAfter: true, decimalSeparator: ",", groupSeparator: "\u00a0"},
+	"zh": {decimalSeparator: ".", groupSeparator: ","},
+}
+
+// currencySymbols contains the symbols of common currencies, keyed by ISO 4217
+// currency code. Other currencies are written with their code.
+var currencySymbols = map[string]string{
+	"CNY": "¥",
+	"EUR": "€",
+	"GBP": "£",
+	"INR": "₹",
+	"JPY": "¥",
+	"KRW": "₩",
+	"RUB": "₽",
+	"USD": "$",
+}
+
+// currencyDecimals contains the number of decimals of currencies that do not
+// use two decimals, keyed by ISO 4217 currency code.
+var currencyDecimals = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+}
+
+// FormatNumber returns n formatted according to the language’s conventions,
+// e.g. “1,234.5” for “en” and “1.234,5” for “de”. The format is chosen by the
+// base language of Code, e.g. “de” for “de-AT”. Unknown languages use the
+// English format.
+func (l *Language) FormatNumber(n float64) string {
+	return l.numberFormat().format(n, -1)
+}
+
+// FormatCurrency returns amount formatted as an amount of the currency
+// identified by the ISO 4217 currency code currencyCode, e.g. “$1,234.50” for
+// “en” and “1.234,50 $” for “de”. The amount is rounded to the currency’s
+// number of decimals, which is two for most currencies. Currencies without a
+// known symbol are written with their code, e.g. “CHF 1,234.50”.
+func (l *Language) FormatCurrency(amount float64, currencyCode string) string {
+	currencyCode = strings.ToUpper(currencyCode)
+	format := l.numberFormat()
+
+	decimals, ok := currencyDecimals[currencyCode]
+	if !ok {
+		decimals = 2
+	}
+
+	number := format.format(math.Abs(amount), decimals)
+	sign := ""
+	if amount < 0 && strings.Trim(number, "0"+format.decimalSeparator+format.groupSeparator) != "" {
+		sign = "-"
+	}
+
+	symbol, ok := currencySymbols[currencyCode]
+	if !ok {
+		symbol = currencyCode
+	}
+
+	if format.currencyAfter {
+		return sign + number + "\u00a0" + symbol
+	}
+	if !ok {
+		return sign + symbol + "\u00a0" + number
+	}
+	return sign + symbol + number
+}
+
+// numberFormat returns the number format of the language.
+func (l *Language) numberFormat() numberFormat {
+	code := strings.ToLower(l.Code)
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+
+	if format, ok := numberFormats[code]; ok {
+		return format
+	}
+	return numberFormats["en"]
+}
+
+// format returns n with the specified number of decimals, or as many as
+// necessary if decimals is -1, using the format’s separators.
+func (f numberFormat) format(n float64, decimals int) string {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+
+	s := strconv.FormatFloat(n, 'f', decimals, 64)
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	integer, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		integer, fraction = s[:i], s[i+1:]
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(f.groupSeparator)
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteString(f.decimalSeparator)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package languages
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLanguage_FormatNumber(t *testing.T) {
+	tests := []struct {
+		code     string
+		n        float64
+		expected string
+	}{
+		{"en", 0, "0"},
+		{"en", 123, "123"},
+		{"en", 1234, "1,234"},
+		{"en", -1234567.891, "-1,234,567.891"},
+		{"en-US", 1234.5, "1,234.5"},
+		{"de", 1234567.5, "1.234.567,5"},
+		{"de-AT", -1234.5, "-1.234,5"},
+		{"fr", 1234.5, "1\u00a0234,5"},
+		{"xx", 1234.5, "1,234.5"},
+		{"en", math.Inf(1), "+Inf"},
+	}
+
+	for _, test := range tests {
+		language := NewLanguage(test.code, "")
+		if result := language.FormatNumber(test.n); result != test.expected {
+			t.Errorf("%s: Expected %q for %v, got %q", test.code, test.expected, test.n, result)
+		}
+	}
+}
+
+func TestLanguage_FormatCurrency(t *testing.T) {
+	tests := []struct {
+		code         string
+		amount       float64
+		currencyCode string
+		expected     string
+	}{
+		{"en", 1234.5, "USD", "$1,234.50"},
+		{"en", -1234.5, "usd", "-$1,234.50"},
+		{"en", 1234.5, "CHF", "CHF\u00a01,234.50"},
+		{"en", 1234.6, "JPY", "¥1,235"},
+		{"en", -0.001, "EUR", "€0.00"},
+		{"de", 1234.5, "EUR", "1.234,50\u00a0€"},
+		{"de", -1234.5, "CHF", "-1.234,50\u00a0CHF"},
+		{"ja", 1234, "JPY", "¥1,234"},
+	}
+
+	for _, test := range tests {
+		language := NewLanguage(test.code, "")
+		if result := language.FormatCurrency(test.amount, test.currencyCode); result != test.expected {
+			t.Errorf("%s: Expected %q for %v %s, got %q", test.code, test.expected, test.amount, test.currencyCode, result)
+		}
+	}
+}
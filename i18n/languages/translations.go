@@ -12,3 +12,18 @@ type Translation struct {
 	Many,
 	Other *template.Template
 }
+
+// pluralGroup returns the template of the plural group that matches count. Zero,
+// One and Two are used for the quantities 0, 1 and 2 if they are set. In all
+// other cases, Other is used.
+func (t *Translation) pluralGroup(count int) *template.Template {
+	switch {
+	case count == 0 && t.Zero != nil:
+		return t.Zero
+	case count == 1 && t.One != nil:
+		return t.One
+	case count == 2 && t.Two != nil:
+		return t.Two
+	}
+	return t.Other
+}
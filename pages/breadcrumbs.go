@@ -1,6 +1,10 @@
 package pages
 
-import "net/url"
+import (
+	"encoding/json"
+	"html/template"
+	"net/url"
+)
 
 // Breadcrumbs manages navigation breadcrumbs.
 type Breadcrumbs []*Breadcrumb
@@ -26,6 +30,51 @@ func (b *Breadcrumbs) GetAll() []*Breadcrumb {
 	return []*Breadcrumb(*b)
 }
 
+// JSONLD returns a <script type="application/ld+json"> element that describes
+// the breadcrumbs as schema.org BreadcrumbList, so search engines can show
+// them in search results. It can be called from templates, e.g.
+// “{{.Breadcrumbs.JSONLD}}”. Search engines expect absolute URLs. The URL of
+// the last breadcrumb, the current page, can be nil. If there are no
+// breadcrumbs, an empty string is returned.
+func (b *Breadcrumbs) JSONLD() (template.HTML, error) {
+	if len(*b) == 0 {
+		return "", nil
+	}
+
+	type listItem struct {
+		Type     string `json:"@type"`
+		Position int    `json:"position"`
+		Name     string `json:"name"`
+		Item     string `json:"item,omitempty"`
+	}
+
+	items := make([]listItem, 0, len(*b))
+	for i, breadcrumb := range *b {
+		item := listItem{
+			Type:     "ListItem",
+			Position: i + 1,
+			Name:     breadcrumb.Title,
+		}
+		if breadcrumb.URL != nil {
+			item.Item = breadcrumb.URL.String()
+		}
+		items = append(items, item)
+	}
+
+	// json.Marshal escapes “<”, “>” and “&”, so the JSON cannot end the
+	// script element early.
+	data, err := json.Marshal(struct {
+		Context         string     `json:"@context"`
+		Type            string     `json:"@type"`
+		ItemListElement []listItem `json:"itemListElement"`
+	}{"https://schema.org", "BreadcrumbList", items})
+	if err != nil {
+		return "", err
+	}
+
+	return template.HTML(`<script type="application/ld+json">` + string(data) + `</script>`), nil
+}
+
 // Remove removes breadcrumbs.
 func (b *Breadcrumbs) Remove(breadcrumbs ...*Breadcrumb) {
 	bb := b.GetAll()
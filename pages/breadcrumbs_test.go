@@ -60,3 +60,23 @@ func TestBreadcrumbs_RemoveAll(t *testing.T) {
 		t.Errorf("Expected %v, got %v", expected, result)
 	}
 }
+
+func TestBreadcrumbs_JSONLD(t *testing.T) {
+	breadcrumbs := &Breadcrumbs{}
+	if result, err := breadcrumbs.JSONLD(); err != nil || result != "" {
+		t.Errorf("Expected empty string, got %q, %v", result, err)
+	}
+
+	breadcrumbs.AddNew("Home", &url.URL{Scheme: "https", Host: "example.com", Path: "/"})
+	breadcrumbs.AddNew("</script>", nil)
+
+	expected := `<script type="application/ld+json">{"@context":"https://schema.org","@type":"BreadcrumbList","itemListElement":[` +
+		`{"@type":"ListItem","position":1,"name":"Home","item":"https://example.com/"},` +
+		`{"@type":"ListItem","position":2,"name":"\u003c/script\u003e"}]}</script>`
+
+	if result, err := breadcrumbs.JSONLD(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if string(result) != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, result)
+	}
+}
@@ -0,0 +1,182 @@
+package pages
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores rendered pages, so pages that are the same for all users for a
+// while can be served without rendering them on every request. See
+// Page.ServeCached. A Cache is safe for concurrent use.
+type Cache struct {
+	// Gzip determines whether pages are also stored gzip-compressed. Clients
+	// that accept gzip encoding are then served the compressed page.
+	Gzip bool
+
+	// TTL is the duration for which a rendered page is served from the cache.
+	TTL time.Duration
+
+	entries map[string]*cacheEntry
+	mutex   sync.Mutex
+
+	// clock replaces time.Now when computing when pages expire, e.g. in
+	// tests. If it is nil, time.Now is used.
+	clock func() time.Time
+}
+
+// cacheEntry is a rendered page. ready is closed once the page was rendered,
+// after which the other fields must not be changed.
+type cacheEntry struct {
+	body    []byte
+	err     error
+	expires time.Time
+	gzipped []byte
+	ready   chan struct{}
+}
+
+// NewCache returns a new instance of Cache that serves rendered pages for the
+// duration of ttl.
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		TTL:     ttl,
+		entries: make(map[string]*cacheEntry),
+	}
+}
+
+// Invalidate removes the pages stored under the provided keys, so they are
+// rendered again on the next request.
+func (c *Cache) Invalidate(keys ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+}
+
+// InvalidateAll removes all pages.
+func (c *Cache) InvalidateAll() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = make(map[string]*cacheEntry)
+}
+
+// get returns the page stored under key. If there is none or it expired, the
+// page is rendered with render. While a page is rendered, other callers asking
+// for the same key wait for the result instead of rendering the page, too.
+func (c *Cache) get(key string, render func() ([]byte, error)) (*cacheEntry, error) {
+	c.mutex.Lock()
+
+	if entry, ok := c.entries[key]; ok {
+		select {
+		case <-entry.ready:
+			if entry.err == nil && c.now().Before(entry.expires) {
+				c.mutex.Unlock()
+				return entry, nil
+			}
+		default:
+			c.mutex.Unlock()
+			<-entry.ready
+			return entry, entry.err
+		}
+	}
+
+	entry := &cacheEntry{ready: make(chan struct{})}
+	if c.entries == nil {
+		c.entries = make(map[string]*cacheEntry)
+	}
+	c.entries[key] = entry
+	c.mutex.Unlock()
+
+	entry.body, entry.err = render()
+	if entry.err == nil && c.Gzip {
+		entry.gzipped, entry.err = gzipBytes(entry.body)
+	}
+	entry.expires = c.now().Add(c.TTL)
+	close(entry.ready)
+
+	if entry.err != nil {
+		c.mutex.Lock()
+		if c.entries[key] == entry {
+			delete(c.entries, key)
+		}
+		c.mutex.Unlock()
+	}
+	return entry, entry.err
+}
+
+// now returns the time used to check and set expiry dates of entries.
+func (c *Cache) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock()
+}
+
+// ServeCached serves the page like Serve, but takes the rendered page from
+// cache, where it is stored under key. If the page is not in the cache or
+// expired, it is rendered and stored. Because all requests with the same key
+// are served the same page, key must contain everything the page depends on,
+// e.g. the language. For the same reason, forms in cached pages get no CSRF
+// token, so pages with forms that must be protected should not be cached.
+func (p *Page) ServeCached(cache *Cache, key string) error {
+	entry, err := cache.get(key, p.render)
+	if err != nil {
+		return err
+	}
+
+	p.writeHeader()
+	body := entry.body
+
+	// net/http would sniff the content type of gzipped bodies as
+	// application/x-gzip.
+	if p.writer.Header().Get("Content-Type") == "" {
+		p.writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+
+	if entry.gzipped != nil {
+		p.writer.Header().Add("Vary", "Accept-Encoding")
+		if acceptsGzip(p.request) {
+			p.writer.Header().Set("Content-Encoding", "gzip")
+			body = entry.gzipped
+		}
+	}
+
+	_, err = p.writer.Write(body)
+	return err
+}
+
+// acceptsGzip returns whether the client accepts gzip-encoded responses.
+func acceptsGzip(request *http.Request) bool {
+	for _, encoding := range strings.Split(request.Header.Get("Accept-Encoding"), ",") {
+		fields := strings.Split(encoding, ";")
+		if strings.TrimSpace(fields[0]) != "gzip" {
+			continue
+		}
+		for _, field := range fields[1:] {
+			if q := strings.Replace(field, " ", "", -1); q == "q=0" || q == "q=0.0" || q == "q=0.00" || q == "q=0.000" {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipBytes returns b gzip-compressed.
+func gzipBytes(b []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+
+	if _, err := writer.Write(b); err != nil {
+		return nil, err
+	} else if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,168 @@
+package pages
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ChristianSiegert/go-packages/sessions/sessionstest"
+)
+
+func TestPage_ServeCached(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "page.html")
+	if err := ioutil.WriteFile(path, []byte("<p>{{.Data.Count}}</p>"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl := MustNewTemplate(nil, path)
+	cache := NewCache(time.Minute)
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.clock = func() time.Time { return now }
+
+	var renders int32
+	serve := func(acceptEncoding string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("Accept-Encoding", acceptEncoding)
+
+		page := NewPage(recorder, request, tpl)
+		page.Data["Count"] = atomic.AddInt32(&renders, 1)
+
+		if err := page.ServeCached(cache, "key"); err != nil {
+			t.Errorf("ServeCached failed unexpectedly: %s", err)
+		}
+		return recorder
+	}
+
+	// All concurrent requests are served the page rendered first.
+	bodies := make([]string, 10)
+	var wg sync.WaitGroup
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bodies[i] = serve("").Body.String()
+		}(i)
+	}
+	wg.Wait()
+
+	first := bodies[0]
+	for _, body := range bodies {
+		if body != first {
+			t.Errorf("Expected cached page %q, got %q", first, body)
+		}
+	}
+
+	now = now.Add(2 * time.Minute)
+	if body := serve("").Body.String(); body == first {
+		t.Errorf("Expected page to be rendered again after expiry.")
+	}
+
+	cache.Invalidate("key")
+	cache.Gzip = true
+	serve("")
+
+	recorder := serve("gzip, deflate")
+	if encoding := recorder.Header().Get("Content-Encoding"); encoding != "gzip" {
+		t.Fatalf("Expected gzip encoding, got %q", encoding)
+	} else if contentType := recorder.Header().Get("Content-Type"); contentType != "text/html; charset=utf-8" {
+		t.Errorf("Expected content type %q, got %q", "text/html; charset=utf-8", contentType)
+	}
+
+	reader, err := gzip.NewReader(recorder.Body)
+	if err != nil {
+		t.Fatalf("Reading gzip failed unexpectedly: %s", err)
+	}
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Reading gzip failed unexpectedly: %s", err)
+	}
+
+	if plain := serve("gzip;q=0"); plain.Header().Get("Content-Encoding") != "" {
+		t.Errorf("Expected no encoding for gzip;q=0.")
+	} else if plain.Body.String() != string(body) {
+		t.Errorf("Expected %q, got %q", body, plain.Body.String())
+	}
+}
+
+func TestPage_ServeCached_csrfToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "page.html")
+	if err := ioutil.WriteFile(path, []byte(`{{.Form.Render "/save" "post"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl := MustNewTemplate(nil, path)
+	cache := NewCache(time.Minute)
+	store := sessionstest.New()
+
+	var tokens []string
+	for i := 0; i < 2; i++ {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", "/", nil)
+
+		page := NewPage(recorder, request, tpl)
+		if page.Session, err = store.Get(recorder, request); err != nil {
+			t.Fatal(err)
+		}
+		token, err := page.Session.CSRFToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens = append(tokens, token)
+
+		if err := page.ServeCached(cache, "key"); err != nil {
+			t.Fatalf("ServeCached failed unexpectedly: %s", err)
+		}
+
+		body := recorder.Body.String()
+		for _, token := range tokens {
+			if strings.Contains(body, token) {
+				t.Errorf("Expected cached page %q not to contain CSRF token %q", body, token)
+			}
+		}
+	}
+
+	if store.Len() != 0 {
+		t.Errorf("Expected no session to be saved, got %d", store.Len())
+	}
+}
+
+func TestCache_zeroValue(t *testing.T) {
+	cache := &Cache{TTL: time.Minute}
+
+	renders := 0
+	render := func() ([]byte, error) {
+		renders++
+		return []byte("foo"), nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if entry, err := cache.get("key", render); err != nil {
+			t.Fatalf("get failed unexpectedly: %s", err)
+		} else if string(entry.body) != "foo" {
+			t.Errorf("Expected %q, got %q", "foo", entry.body)
+		}
+	}
+	if renders != 1 {
+		t.Errorf("Expected 1 render, got %d", renders)
+	}
+}
@@ -0,0 +1,78 @@
+package pages
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Negotiate serves the page as HTML like Serve, or jsonData encoded as JSON,
+// depending on the request’s Accept header. JSON is served if the client
+// prefers “application/json” over “text/html”. If both are equally
+// acceptable, e.g. because the header is missing or “*/*”, HTML is served.
+// Either way, the page’s header is written, and “Accept” is added to the Vary
+// header, so caches keep both responses apart.
+func (p *Page) Negotiate(jsonData interface{}) error {
+	p.header.Add("Vary", "Accept")
+
+	accept := p.request.Header.Get("Accept")
+	if acceptQuality(accept, "application/json") <= acceptQuality(accept, "text/html") {
+		return p.Serve()
+	}
+
+	b, err := json.Marshal(jsonData)
+	if err != nil {
+		return err
+	}
+
+	p.writeHeader()
+	p.writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, err = bytes.NewBuffer(b).WriteTo(p.writer)
+	return err
+}
+
+// acceptQuality returns the quality value that header, the value of an
+// “Accept” HTTP header, assigns to mediaType, e.g. “text/html”. The most
+// specific matching media range counts, e.g. “text/html” before “text/*”
+// before “*/*”. If header is empty, all media types have quality value 1. If
+// no media range matches, the quality value is 0.
+func acceptQuality(header, mediaType string) float64 {
+	if strings.TrimSpace(header) == "" {
+		return 1
+	}
+
+	mainType := mediaType[:strings.Index(mediaType, "/")+1]
+	quality, specificity := 0.0, 0
+
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		mediaRange := strings.ToLower(strings.TrimSpace(fields[0]))
+
+		s := 0
+		switch mediaRange {
+		case mediaType:
+			s = 3
+		case mainType + "*":
+			s = 2
+		case "*/*":
+			s = 1
+		}
+		if s <= specificity {
+			continue
+		}
+
+		q := 1.0
+		for _, field := range fields[1:] {
+			field = strings.TrimSpace(field)
+			if !strings.HasPrefix(field, "q=") {
+				continue
+			}
+			if value, err := strconv.ParseFloat(field[2:], 64); err == nil {
+				q = value
+			}
+		}
+		quality, specificity = q, s
+	}
+	return quality
+}
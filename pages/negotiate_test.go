@@ -0,0 +1,56 @@
+package pages
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPage_Negotiate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "page.html")
+	if err := ioutil.WriteFile(path, []byte("<p>{{.Data.Name}}</p>"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tpl := MustNewTemplate(nil, path)
+
+	tests := []struct {
+		accept       string
+		expectedBody string
+		expectedType string
+	}{
+		{"", "<p>Jane</p>", ""},
+		{"*/*", "<p>Jane</p>", ""},
+		{"text/html,application/xhtml+xml,*/*;q=0.8", "<p>Jane</p>", ""},
+		{"application/json", `{"name":"Jane"}`, "application/json; charset=utf-8"},
+		{"application/json, text/html;q=0.5", `{"name":"Jane"}`, "application/json; charset=utf-8"},
+		{"text/html;q=0.5, application/*", `{"name":"Jane"}`, "application/json; charset=utf-8"},
+		{"text/html, application/json", "<p>Jane</p>", ""},
+	}
+
+	for i, test := range tests {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("Accept", test.accept)
+
+		page := NewPage(recorder, request, tpl)
+		page.Data["Name"] = "Jane"
+
+		if err := page.Negotiate(map[string]string{"name": "Jane"}); err != nil {
+			t.Errorf("%d. Negotiate failed unexpectedly: %s", i, err)
+		} else if body := recorder.Body.String(); body != test.expectedBody {
+			t.Errorf("%d. Expected body %q, got %q", i, test.expectedBody, body)
+		} else if contentType := recorder.Header().Get("Content-Type"); test.expectedType != "" && contentType != test.expectedType {
+			t.Errorf("%d. Expected content type %q, got %q", i, test.expectedType, contentType)
+		} else if vary := recorder.Header().Get("Vary"); vary != "Accept" {
+			t.Errorf("%d. Expected Vary header %q, got %q", i, "Accept", vary)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"html/template"
+	"io"
 	"net/http"
 	"path"
 
@@ -27,12 +29,22 @@ type Page struct {
 	// Breadcrumbs represent a hierarchical navigation.
 	Breadcrumbs *Breadcrumbs
 
+	// csrfErr is the error that occurred while generating the CSRF token on
+	// demand, see generateCSRFToken.
+	csrfErr error
+
+	// csrfGenerated is true if a CSRF token was generated for p.Session while
+	// rendering the page, so the session must be saved.
+	csrfGenerated bool
+
 	// Data for populating the template.
 	Data map[string]interface{}
 
 	// Form helper for creating HTML input elements in the template.
 	Form *forms.Form
 
+	header http.Header
+
 	// Language to use for displaying text.
 	Language *languages.Language
 
@@ -60,6 +72,7 @@ func NewPage(writer http.ResponseWriter, request *http.Request, tpl *Template) *
 		Breadcrumbs: &Breadcrumbs{},
 		Data:        make(map[string]interface{}),
 		Form:        forms.New(request),
+		header:      make(http.Header),
 		request:     request,
 		Template:    tpl,
 		writer:      writer,
@@ -92,39 +105,166 @@ func (p *Page) FlashAll() ([]sessions.Flash, error) {
 	return flashes, nil
 }
 
+// Header returns the header that is sent with the page, e.g. for setting
+// Cache-Control or security headers. Changes to the header are applied when
+// the page is served or redirected.
+func (p *Page) Header() http.Header {
+	return p.header
+}
+
 // Redirect redirects the client to destination, using code as HTTP status code.
 // If args is provided, destination is formatted with fmt.Sprintf, to which args
-// is passed. destination is automatically prefixed with p.BaseURL.
+// is passed. destination is automatically prefixed with p.BaseURL. If p.Session
+// was changed, e.g. by adding a flash to be shown after the redirect, it is
+// saved first.
 func (p *Page) Redirect(code int, destination string, args ...interface{}) error {
 	if len(args) > 0 {
 		destination = fmt.Sprintf(destination, args...)
 	}
+
+	if p.Session != nil && p.Session.IsDirty() {
+		if err := p.Session.Save(p.writer); err != nil {
+			return errors.New("pages: saving session failed: " + err.Error())
+		}
+	}
+
+	p.writeHeader()
 	http.Redirect(p.writer, p.request, p.BaseURL+destination, code)
 	return nil
 }
 
-// Serve serves the page.
+// Render renders the page like Serve, but writes it to writer instead of the
+// response, e.g. for sending the page as HTML email or saving a preview. The
+// page’s header is not written and the session is not saved, so forms only get
+// a CSRF token if the session already has one.
+func (p *Page) Render(writer io.Writer) error {
+	p.readCSRFToken()
+	b, err := p.render()
+	if err != nil {
+		return err
+	}
+
+	_, err = bytes.NewBuffer(b).WriteTo(writer)
+	return err
+}
+
+// Serve serves the page. The page is rendered into a buffer, whitespace is
+// removed, and then the page is written to the response. If rendering fails,
+// nothing is written, so an error page can be served instead. If a form is
+// rendered with Form.Render or Form.CSRF and p.Session has no CSRF token yet,
+// one is generated and the session is saved.
 func (p *Page) Serve() error {
+	p.generateCSRFToken()
+	b, err := p.render()
+	if err != nil {
+		return err
+	}
+
+	if p.csrfErr != nil {
+		return p.csrfErr
+	} else if p.csrfGenerated {
+		if err := p.Session.Save(p.writer); err != nil {
+			return errors.New("pages: saving session failed: " + err.Error())
+		}
+	}
+
+	p.writeHeader()
+	_, err = bytes.NewBuffer(b).WriteTo(p.writer)
+	return err
+}
+
+// render renders the page and removes whitespace from it.
+func (p *Page) render() ([]byte, error) {
+	tpl, err := p.template()
+	if err != nil {
+		return nil, err
+	}
+
 	buffer := bytes.NewBuffer([]byte{})
+	if err := p.execute(tpl, buffer); err != nil {
+		return nil, err
+	}
+
+	return html.RemoveWhitespace(buffer.Bytes()), nil
+}
+
+// ServeStreaming serves the page like Serve, but writes the rendered page
+// directly to the response instead of buffering it, which uses less memory for
+// large pages. Whitespace is not removed. Once rendering starts, the header and
+// status code have been sent and cannot be changed, so if rendering fails, the
+// client receives a partial page. For the same reason, the session cannot be
+// saved, so forms only get a CSRF token if the session already has one.
+func (p *Page) ServeStreaming() error {
+	tpl, err := p.template()
+	if err != nil {
+		return err
+	}
+
+	p.readCSRFToken()
+
+	p.writeHeader()
+	return p.execute(tpl, p.writer)
+}
+
+// generateCSRFToken makes forms rendered with p.Form use the CSRF token of
+// p.Session, see readCSRFToken. If the session has no token yet, one is
+// generated when a form first needs it, and csrfGenerated is set, so the
+// session is saved after rendering.
+func (p *Page) generateCSRFToken() {
+	p.readCSRFToken()
+	if p.Session == nil || p.Form == nil || p.Form.CSRFToken != "" || p.Form.CSRFTokenFunc != nil {
+		return
+	}
+
+	p.Form.CSRFTokenFunc = func() string {
+		token, err := p.Session.CSRFToken()
+		if err != nil {
+			p.csrfErr = errors.New("pages: generating CSRF token failed: " + err.Error())
+			return ""
+		}
+		p.csrfGenerated = true
+		return token
+	}
+}
 
+// readCSRFToken sets p.Form.CSRFToken to the CSRF token of p.Session, so forms
+// rendered with Form.Render are protected against cross-site request forgery.
+// No token is generated. If p.Session or p.Form is nil, or the form already
+// has a token, readCSRFToken does nothing.
+func (p *Page) readCSRFToken() {
+	if p.Session == nil || p.Form == nil || p.Form.CSRFToken != "" {
+		return
+	}
+	p.Form.CSRFToken = p.Session.Values().Get(sessions.KeyCSRFToken)
+}
+
+// template returns the page’s template, translated into the page’s language.
+func (p *Page) template() (*template.Template, error) {
 	if p.Template == nil {
-		return errors.New("pages: template is nil")
+		return nil, errors.New("pages: template is nil")
 	}
 
 	if ReloadTemplates {
 		if err := p.Template.Reload(); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
+	return p.Template.translate(p.Language)
+}
+
+// execute renders the page with tpl and writes it to writer.
+func (p *Page) execute(tpl *template.Template, writer io.Writer) error {
 	templateName := path.Base(p.Template.paths[0])
-	if err := p.Template.template.ExecuteTemplate(buffer, templateName, p); err != nil {
-		return err
-	}
+	return tpl.ExecuteTemplate(writer, templateName, p)
+}
 
-	b := html.RemoveWhitespace(buffer.Bytes())
-	_, err := bytes.NewBuffer(b).WriteTo(p.writer)
-	return err
+// writeHeader copies the page’s header to the response header.
+func (p *Page) writeHeader() {
+	header := p.writer.Header()
+	for key, values := range p.header {
+		header[key] = values
+	}
 }
 
 // T returns the translation associated with translationID. If none is
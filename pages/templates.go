@@ -3,18 +3,28 @@ package pages
 import (
 	"errors"
 	"html/template"
+	"sync"
+
+	"github.com/ChristianSiegert/go-packages/i18n/languages"
 )
 
 // Template is a collection of (nested) template files.
 type Template struct {
-	funcMap  template.FuncMap
-	paths    []string
+	funcMap template.FuncMap
+	paths   []string
+
+	// template is the parsed template. It is never executed itself, but
+	// serves as base for the language-specific copies in translated.
 	template *template.Template
+
+	mutex      sync.Mutex
+	translated map[*languages.Language]*template.Template
 }
 
 // NewTemplate creates a template from template files specified by paths. If the
 // template files are supposed to use functions other than the built-in Go
-// functions, these functions must be provided through funcMap.
+// functions and the translation functions provided by FuncMap, these functions
+// must be provided through funcMap.
 func NewTemplate(funcMap template.FuncMap, paths ...string) (*Template, error) {
 	if len(paths) == 0 {
 		return nil, errors.New("pages: no template path provided")
@@ -43,12 +53,79 @@ func MustNewTemplate(funcMap template.FuncMap, paths ...string) *Template {
 
 // Reload parses the template files again.
 func (t *Template) Reload() error {
-	var err error
-	t.template, err = load(t.funcMap, t.paths...)
-	return err
+	tpl, err := load(t.funcMap, t.paths...)
+	if err != nil {
+		return err
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.template = tpl
+	t.translated = nil
+	return nil
+}
+
+// translate returns a copy of the template whose translation functions are
+// bound to language. Functions in t.funcMap are applied again afterwards, so
+// they keep taking precedence. Copies are created once per language and then
+// reused.
+func (t *Template) translate(language *languages.Language) (*template.Template, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if tpl, ok := t.translated[language]; ok {
+		return tpl, nil
+	}
+
+	tpl, err := t.template.Clone()
+	if err != nil {
+		return nil, err
+	}
+	tpl.Funcs(FuncMap(language)).Funcs(t.funcMap)
+
+	if t.translated == nil {
+		t.translated = make(map[*languages.Language]*template.Template)
+	}
+	t.translated[language] = tpl
+	return tpl, nil
+}
+
+// FuncMap returns the translation functions “t” and “tn” and the formatting
+// functions “formatNumber” and “formatCurrency” bound to language. “t” takes a
+// translation ID and optional data, e.g. {{t "greeting"}}. “tn” additionally
+// takes a count that selects the plural group, e.g. {{tn "comments" .Count}}.
+// If language is nil, both functions return the translation ID.
+// “formatNumber” and “formatCurrency” call Language.FormatNumber and
+// Language.FormatCurrency, e.g. {{formatCurrency .Price "EUR"}}. If language
+// is nil, they use the English format. The functions are registered
+// automatically by NewTemplate and bound to Page.Language when the page is
+// served.
+func FuncMap(language *languages.Language) template.FuncMap {
+	formatter := language
+	if formatter == nil {
+		formatter = languages.NewLanguage("en", "English")
+	}
+
+	return template.FuncMap{
+		"formatCurrency": formatter.FormatCurrency,
+		"formatNumber":   formatter.FormatNumber,
+		"t": func(translationID string, data ...map[string]interface{}) string {
+			if language == nil {
+				return translationID
+			}
+			return language.T(translationID, data...)
+		},
+		"tn": func(translationID string, count int, data ...map[string]interface{}) string {
+			if language == nil {
+				return translationID
+			}
+			return language.TN(translationID, count, data...)
+		},
+	}
 }
 
-// load parses all files specified by paths.
+// load parses all files specified by paths. Functions in funcMap take
+// precedence over the translation functions provided by FuncMap.
 func load(funcMap template.FuncMap, paths ...string) (*template.Template, error) {
-	return template.New("root").Funcs(funcMap).ParseFiles(paths...)
+	return template.New("root").Funcs(FuncMap(nil)).Funcs(funcMap).ParseFiles(paths...)
 }
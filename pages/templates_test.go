@@ -0,0 +1,353 @@
+package pages
+
+import (
+	"bytes"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ChristianSiegert/go-packages/i18n/languages"
+	"github.com/ChristianSiegert/go-packages/sessions"
+	"github.com/ChristianSiegert/go-packages/sessions/sessionstest"
+)
+
+func TestPage_Serve_translationFuncs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "page.html")
+	content := `<p>{{t "greeting"}}</p><p>{{tn "comments" 2}}</p><p>{{formatCurrency 1234.5 "EUR"}}</p>`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	german := languages.NewLanguage("de", "German")
+	german.Set("greeting", "Hallo")
+	german.Set("comments", "{{.Count}} Kommentare")
+
+	english := languages.NewLanguage("en", "English")
+	english.Set("greeting", "Hello")
+
+	tpl := MustNewTemplate(nil, path)
+
+	tests := []struct {
+		language *languages.Language
+		expected string
+	}{
+		{nil, "<p>greeting</p><p>comments</p><p>€1,234.50</p>"},
+		{german, "<p>Hallo</p><p>2 Kommentare</p><p>1.234,50\u00a0€</p>"},
+		{english, "<p>Hello</p><p>comments</p><p>€1,234.50</p>"},
+	}
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", "/", nil)
+
+		page := NewPage(recorder, request, tpl)
+		page.Language = test.language
+
+		if err := page.Serve(); err != nil {
+			t.Fatalf("Serving page failed unexpectedly: %s", err)
+		}
+
+		if result := recorder.Body.String(); result != test.expected {
+			t.Errorf("Expected %q, got %q", test.expected, result)
+		}
+	}
+}
+
+func TestPage_Header(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "page.html")
+	if err := ioutil.WriteFile(path, []byte("<p>foo</p>"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl := MustNewTemplate(nil, path)
+
+	for _, redirect := range []bool{false, true} {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", "/", nil)
+
+		page := NewPage(recorder, request, tpl)
+		page.Header().Set("Cache-Control", "no-store")
+		page.Header().Add("X-Custom", "a")
+		page.Header().Add("X-Custom", "b")
+
+		if recorder.Header().Get("Cache-Control") != "" {
+			t.Errorf("Expected header not to be written before serving.")
+		}
+
+		if redirect {
+			err = page.Redirect(http.StatusSeeOther, "/bar")
+		} else {
+			err = page.Serve()
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		header := recorder.Result().Header
+		if result := header.Get("Cache-Control"); result != "no-store" {
+			t.Errorf("Expected Cache-Control %q, got %q", "no-store", result)
+		} else if result := header["X-Custom"]; !reflect.DeepEqual(result, []string{"a", "b"}) {
+			t.Errorf("Expected X-Custom %v, got %v", []string{"a", "b"}, result)
+		}
+	}
+}
+
+func TestPage_ServeStreaming(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "page.html")
+	content := "<ul>\n{{range .Data.Items}}  <li>{{.}}</li>\n{{end}}</ul>\n{{index .Data.Items 2}}"
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl := MustNewTemplate(nil, path)
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest("GET", "/", nil)
+
+	page := NewPage(recorder, request, tpl)
+	page.Data = map[string]interface{}{"Items": []string{"a", "b"}}
+	page.Header().Set("Cache-Control", "no-store")
+
+	// Rendering fails at the end, after the list was written.
+	if err := page.ServeStreaming(); err == nil {
+		t.Errorf("Expected error.")
+	}
+
+	expected := "<ul>\n  <li>a</li>\n  <li>b</li>\n</ul>\n"
+	if result := recorder.Body.String(); result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	} else if result := recorder.Result().Header.Get("Cache-Control"); result != "no-store" {
+		t.Errorf("Expected Cache-Control %q, got %q", "no-store", result)
+	}
+}
+
+func TestPage_Render(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "page.html")
+	content := "<p>\n  <b>{{t \"greeting\"}}, {{.Data.Name}}</b>\n</p>"
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	german := languages.NewLanguage("de", "German")
+	german.Set("greeting", "Hallo")
+
+	recorder := httptest.NewRecorder()
+	page := NewPage(recorder, httptest.NewRequest("GET", "/", nil), MustNewTemplate(nil, path))
+	page.Data = map[string]interface{}{"Name": "Anna"}
+	page.Language = german
+
+	var buffer bytes.Buffer
+	if err := page.Render(&buffer); err != nil {
+		t.Fatalf("Rendering page failed unexpectedly: %s", err)
+	}
+
+	if expected, result := "<p><b>Hallo, Anna</b></p>", buffer.String(); result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	} else if recorder.Body.Len() != 0 {
+		t.Errorf("Expected nothing to be written to the response, got %q", recorder.Body.String())
+	}
+}
+
+func TestPage_Serve_customTranslationFunc(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "page.html")
+	if err := ioutil.WriteFile(path, []byte(`<p>{{t "x"}}</p>`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl := MustNewTemplate(template.FuncMap{
+		"t": func(translationID string) string { return "custom-" + translationID },
+	}, path)
+
+	for _, language := range []*languages.Language{nil, languages.NewLanguage("de", "German")} {
+		recorder := httptest.NewRecorder()
+		page := NewPage(recorder, httptest.NewRequest("GET", "/", nil), tpl)
+		page.Language = language
+
+		if err := page.Serve(); err != nil {
+			t.Fatalf("Serving page failed unexpectedly: %s", err)
+		} else if expected := "<p>custom-x</p>"; recorder.Body.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, recorder.Body.String())
+		}
+	}
+}
+
+func TestPage_Serve_csrfToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "page.html")
+	if err := ioutil.WriteFile(path, []byte(`{{.Form.Render "/save" "post"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl := MustNewTemplate(nil, path)
+	store := sessionstest.New()
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest("GET", "/", nil)
+
+	page := NewPage(recorder, request, tpl)
+	if err := page.Serve(); err != nil {
+		t.Fatalf("Serving page failed unexpectedly: %s", err)
+	} else if expected := `<form action="/save" method="post"></form>`; recorder.Body.String() != expected {
+		t.Errorf("Expected %q without session, got %q", expected, recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	page = NewPage(recorder, request, tpl)
+	if page.Session, err = store.Get(recorder, request); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := page.Serve(); err != nil {
+		t.Fatalf("Serving page failed unexpectedly: %s", err)
+	}
+
+	token := page.Session.Values().Get(sessions.KeyCSRFToken)
+	if token == "" {
+		t.Fatal("Expected CSRF token to be generated.")
+	} else if store.Len() != 1 {
+		t.Errorf("Expected session to be saved.")
+	}
+
+	expected := `<form action="/save" method="post"><input name="csrf_token" type="hidden" value="` + token + `"></form>`
+	if result := recorder.Body.String(); result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestPage_Serve_csrfTokenNotNeeded(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "page.html")
+	if err := ioutil.WriteFile(path, []byte(`<p>foo</p>`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl := MustNewTemplate(nil, path)
+	store := sessionstest.New()
+	request := httptest.NewRequest("GET", "/", nil)
+
+	recorder := httptest.NewRecorder()
+	page := NewPage(recorder, request, tpl)
+	if page.Session, err = store.Get(recorder, request); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := page.Serve(); err != nil {
+		t.Fatalf("Serving page failed unexpectedly: %s", err)
+	} else if page.Session.Values().Get(sessions.KeyCSRFToken) != "" {
+		t.Errorf("Expected no CSRF token for page without form.")
+	} else if store.Len() != 0 || len(recorder.Result().Cookies()) != 0 {
+		t.Errorf("Expected session not to be saved.")
+	}
+}
+
+func TestPage_Render_csrfToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "page.html")
+	if err := ioutil.WriteFile(path, []byte(`{{.Form.Render "/save" "post"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl := MustNewTemplate(nil, path)
+	store := sessionstest.New()
+	request := httptest.NewRequest("GET", "/", nil)
+
+	recorder := httptest.NewRecorder()
+	page := NewPage(recorder, request, tpl)
+	if page.Session, err = store.Get(recorder, request); err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+	if err := page.Render(&buffer); err != nil {
+		t.Fatalf("Rendering page failed unexpectedly: %s", err)
+	} else if expected := `<form action="/save" method="post"></form>`; buffer.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buffer.String())
+	} else if store.Len() != 0 || len(recorder.Result().Cookies()) != 0 {
+		t.Errorf("Expected session not to be saved.")
+	}
+}
+
+func TestPage_Redirect_savesSession(t *testing.T) {
+	store := sessionstest.New()
+	request := httptest.NewRequest("POST", "/", nil)
+
+	recorder := httptest.NewRecorder()
+	page := NewPage(recorder, request, nil)
+	session, err := store.Get(recorder, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page.Session = session
+	page.Session.Flashes().AddNew("Saved.")
+
+	if err := page.Redirect(http.StatusSeeOther, "/items"); err != nil {
+		t.Fatalf("Redirect failed unexpectedly: %s", err)
+	} else if recorder.Code != http.StatusSeeOther {
+		t.Errorf("Expected status %d, got %d", http.StatusSeeOther, recorder.Code)
+	} else if len(recorder.Result().Cookies()) != 1 {
+		t.Errorf("Expected session cookie to be set.")
+	}
+
+	result, err := store.GetMulti(nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(result) != 1 || len(result[0].Flashes().GetAll()) != 1 {
+		t.Errorf("Expected saved session with 1 flash.")
+	}
+
+	calls := len(store.Calls())
+	if err := page.Redirect(http.StatusSeeOther, "/items"); err != nil {
+		t.Fatalf("Redirect failed unexpectedly: %s", err)
+	} else if len(store.Calls()) != calls {
+		t.Errorf("Expected unchanged session not to be saved again.")
+	}
+}
@@ -6,22 +6,49 @@ import (
 	"errors"
 	"net/http"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 )
 
+// timeType is the type of time.Time.
+var timeType = reflect.TypeOf(time.Time{})
+
 // Parser parses httprouter, POST, PUT, GET, etc., parameters.
 type Parser struct {
 	// AfterParse is called after Parse executed successfully. It is useful for
 	// operations that should occur after parsing, like validation.
 	AfterParse func(dest interface{}) error
 
+	// FalseValues are the parameter values that are converted to false,
+	// ignoring case. An empty value is always false.
+	FalseValues []string
+
+	// StrictBools, if true, makes parsing fail if a boolean parameter has a
+	// value that is neither in TrueValues nor in FalseValues. Otherwise, such
+	// values are false.
+	StrictBools bool
+
+	// TrueValues are the parameter values that are converted to true, ignoring
+	// case.
+	TrueValues []string
+
 	request      *http.Request
 	routerParams httprouter.Params
 }
 
+// DefaultFalseValues and DefaultTrueValues are the values of
+// Parser.FalseValues and Parser.TrueValues of parsers returned by NewParser.
+// “on” is the value that browsers send for checked checkboxes without value
+// attribute.
+var (
+	DefaultFalseValues = []string{"0", "false", "no", "off"}
+	DefaultTrueValues  = []string{"1", "true", "yes", "on"}
+)
+
 // NewParser returns a new Parser.
 func NewParser(request *http.Request, params httprouter.Params) (*Parser, error) {
 	if request.Form == nil {
@@ -31,6 +58,8 @@ func NewParser(request *http.Request, params httprouter.Params) (*Parser, error)
 	}
 
 	return &Parser{
+		FalseValues:  DefaultFalseValues,
+		TrueValues:   DefaultTrueValues,
 		request:      request,
 		routerParams: params,
 	}, nil
@@ -40,17 +69,114 @@ func NewParser(request *http.Request, params httprouter.Params) (*Parser, error)
 // a corresponding parameter, converts the parameter from string to the struct
 // field’s type and writes it to the struct field. A struct field and parameter
 // correspond when the parameter name matches the lowercased struct field name.
+//
+// Struct fields that are slices of structs are populated from indexed
+// parameters, e.g. “items[0].name”, “items[0].qty” and “items[1].name” for a
+// field named “items”. Parameters with the same index are parsed into the same
+// slice element. Elements are ordered by index, gaps between indices are
+// skipped.
 func (p *Parser) Parse(dest interface{}) error {
+	var form map[string][]string
+	if p.request != nil {
+		form = p.request.Form
+	}
+	return p.parse(dest, form)
+}
+
+// ParseQuery is like Parse, but only reads httprouter parameters and URL query
+// parameters. Parameters in the request body are ignored.
+func (p *Parser) ParseQuery(dest interface{}) error {
+	var query map[string][]string
+	if p.request != nil && p.request.URL != nil {
+		query = p.request.URL.Query()
+	}
+	return p.parse(dest, query)
+}
+
+// kindTypes contains the types that ParseWithSchema converts parameters to.
+var kindTypes = map[reflect.Kind]reflect.Type{
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.String:  reflect.TypeOf(""),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+}
+
+// ParseWithSchema is like Parse, but for parameters that are only known at
+// runtime. schema maps parameter names to the kind their value is converted
+// to, e.g. reflect.Int. Only kinds of booleans, numbers and strings are
+// supported. The returned map contains the converted value of each parameter
+// in schema that was provided. AfterParse is not called.
+func (p *Parser) ParseWithSchema(schema map[string]reflect.Kind) (map[string]interface{}, error) {
+	var form map[string][]string
+	if p.request != nil {
+		form = p.request.Form
+	}
+
+	values := make(map[string]interface{}, len(schema))
+	for name, kind := range schema {
+		t, ok := kindTypes[kind]
+		if !ok {
+			return nil, errors.New("unsupported kind " + kind.String() + " for parameter " + name)
+		}
+
+		paramValues := p.param(name, form)
+		if len(paramValues) == 0 {
+			continue
+		}
+
+		value := reflect.New(t).Elem()
+		if err := p.setValue(value, paramValues); err != nil {
+			return nil, err
+		}
+		values[name] = value.Interface()
+	}
+	return values, nil
+}
+
+// parse writes httprouter parameters and the parameters in form to dest.
+func (p *Parser) parse(dest interface{}, form map[string][]string) error {
 	v := reflect.ValueOf(dest)
 
 	if v.Kind() != reflect.Ptr || reflect.Indirect(v).Kind() != reflect.Struct {
 		return errors.New("argument must be a pointer to a struct")
 	}
 
-	v = reflect.Indirect(v)
-	t := reflect.TypeOf(v.Interface())
+	param := func(name string) []string {
+		return p.param(name, form)
+	}
+
+	if err := p.parseStruct(reflect.Indirect(v), param, form); err != nil {
+		return err
+	}
+
+	if p.AfterParse != nil {
+		return p.AfterParse(dest)
+	}
+	return nil
+}
+
+// parseStruct writes parameters to the fields of struct v. param returns the
+// values of the named parameter. form contains the parameters that slices of
+// structs are populated from.
+func (p *Parser) parseStruct(v reflect.Value, param func(name string) []string, form map[string][]string) error {
+	t := v.Type()
 
 	for i, j := 0, v.NumField(); i < j; i++ {
+		// Unexported fields cannot be set
+		if t.Field(i).PkgPath != "" {
+			continue
+		}
+
 		// Use field name as parameter name
 		paramName := t.Field(i).Name
 
@@ -59,237 +185,353 @@ func (p *Parser) Parse(dest interface{}) error {
 			paramName = name
 		}
 
-		paramValues := p.param(paramName)
+		if field := v.Field(i); isStructSlice(field.Type()) {
+			if err := p.parseStructSlice(field, paramName, form); err != nil {
+				return err
+			}
+			continue
+		}
+
+		paramValues := param(paramName)
 
 		if len(paramValues) == 0 {
 			continue
 		}
 
-		field := v.Field(i)
+		if err := p.setValue(v.Field(i), paramValues); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		switch field.Type().String() {
-		case "bool":
-			s := strings.ToLower(paramValues[0])
-			b := s == "1" || s == "true" || s == "yes"
-			field.SetBool(b)
-		case "float32":
-			x, err := strconv.ParseFloat(z(paramValues[0]), 32)
+// setValue converts paramValues to the type of field and writes them to field.
+// Fields that are not slices receive the first value.
+func (p *Parser) setValue(field reflect.Value, paramValues []string) error {
+	switch field.Type().String() {
+	case "bool":
+		b, err := p.parseBool(paramValues[0])
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case "float32":
+		x, err := strconv.ParseFloat(z(paramValues[0]), 32)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(x)
+	case "float64":
+		x, err := strconv.ParseFloat(z(paramValues[0]), 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(x)
+	case "int":
+		x, err := strconv.ParseInt(z(paramValues[0]), 10, 0)
+		if err != nil {
+			return err
+		}
+		field.SetInt(x)
+	case "int8":
+		x, err := strconv.ParseInt(z(paramValues[0]), 10, 8)
+		if err != nil {
+			return err
+		}
+		field.SetInt(x)
+	case "int16":
+		x, err := strconv.ParseInt(z(paramValues[0]), 10, 16)
+		if err != nil {
+			return err
+		}
+		field.SetInt(x)
+	case "int32":
+		x, err := strconv.ParseInt(z(paramValues[0]), 10, 32)
+		if err != nil {
+			return err
+		}
+		field.SetInt(x)
+	case "int64":
+		x, err := strconv.ParseInt(z(paramValues[0]), 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(x)
+	case "string":
+		field.SetString(paramValues[0])
+	case "time.Duration":
+		x, err := time.ParseDuration(z(paramValues[0]))
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(x))
+	case "uint":
+		x, err := strconv.ParseUint(z(paramValues[0]), 10, 0)
+		if err != nil {
+			return err
+		}
+		field.SetUint(x)
+	case "uint8":
+		x, err := strconv.ParseUint(z(paramValues[0]), 10, 8)
+		if err != nil {
+			return err
+		}
+		field.SetUint(x)
+	case "uint16":
+		x, err := strconv.ParseUint(z(paramValues[0]), 10, 16)
+		if err != nil {
+			return err
+		}
+		field.SetUint(x)
+	case "uint32":
+		x, err := strconv.ParseUint(z(paramValues[0]), 10, 32)
+		if err != nil {
+			return err
+		}
+		field.SetUint(x)
+	case "uint64":
+		x, err := strconv.ParseUint(z(paramValues[0]), 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetUint(x)
+	case "[]bool":
+		s := make([]bool, 0, len(paramValues))
+		for _, value := range paramValues {
+			b, err := p.parseBool(value)
 			if err != nil {
 				return err
 			}
-			field.SetFloat(x)
-		case "float64":
-			x, err := strconv.ParseFloat(z(paramValues[0]), 64)
+			s = append(s, b)
+		}
+		field.Set(reflect.ValueOf(s))
+	case "[]float32":
+		s := make([]float32, 0, len(paramValues))
+		for _, value := range paramValues {
+			x, err := strconv.ParseFloat(z(value), 32)
 			if err != nil {
 				return err
 			}
-			field.SetFloat(x)
-		case "int":
-			x, err := strconv.ParseInt(z(paramValues[0]), 10, 0)
+			s = append(s, float32(x))
+		}
+		field.Set(reflect.ValueOf(s))
+	case "[]float64":
+		s := make([]float64, 0, len(paramValues))
+		for _, value := range paramValues {
+			x, err := strconv.ParseFloat(z(value), 64)
 			if err != nil {
 				return err
 			}
-			field.SetInt(x)
-		case "int8":
-			x, err := strconv.ParseInt(z(paramValues[0]), 10, 8)
+			s = append(s, x)
+		}
+		field.Set(reflect.ValueOf(s))
+	case "[]int":
+		s := make([]int, 0, len(paramValues))
+		for _, value := range paramValues {
+			x, err := strconv.ParseFloat(z(value), 0)
 			if err != nil {
 				return err
 			}
-			field.SetInt(x)
-		case "int16":
-			x, err := strconv.ParseInt(z(paramValues[0]), 10, 16)
+			s = append(s, int(x))
+		}
+		field.Set(reflect.ValueOf(s))
+	case "[]int8":
+		s := make([]int8, 0, len(paramValues))
+		for _, value := range paramValues {
+			x, err := strconv.ParseFloat(z(value), 8)
 			if err != nil {
 				return err
 			}
-			field.SetInt(x)
-		case "int32":
-			x, err := strconv.ParseInt(z(paramValues[0]), 10, 32)
+			s = append(s, int8(x))
+		}
+		field.Set(reflect.ValueOf(s))
+	case "[]int16":
+		s := make([]int16, 0, len(paramValues))
+		for _, value := range paramValues {
+			x, err := strconv.ParseFloat(z(value), 16)
 			if err != nil {
 				return err
 			}
-			field.SetInt(x)
-		case "int64":
-			x, err := strconv.ParseInt(z(paramValues[0]), 10, 64)
+			s = append(s, int16(x))
+		}
+		field.Set(reflect.ValueOf(s))
+	case "[]int32":
+		s := make([]int32, 0, len(paramValues))
+		for _, value := range paramValues {
+			x, err := strconv.ParseFloat(z(value), 32)
 			if err != nil {
 				return err
 			}
-			field.SetInt(x)
-		case "string":
-			field.SetString(paramValues[0])
-		case "uint":
-			x, err := strconv.ParseUint(z(paramValues[0]), 10, 0)
+			s = append(s, int32(x))
+		}
+		field.Set(reflect.ValueOf(s))
+	case "[]int64":
+		s := make([]int64, 0, len(paramValues))
+		for _, value := range paramValues {
+			x, err := strconv.ParseFloat(z(value), 64)
 			if err != nil {
 				return err
 			}
-			field.SetUint(x)
-		case "uint8":
-			x, err := strconv.ParseUint(z(paramValues[0]), 10, 8)
+			s = append(s, int64(x))
+		}
+		field.Set(reflect.ValueOf(s))
+	case "[]string":
+		field.Set(reflect.ValueOf(paramValues))
+	case "[]time.Duration":
+		s := make([]time.Duration, 0, len(paramValues))
+		for _, value := range paramValues {
+			x, err := time.ParseDuration(z(value))
 			if err != nil {
 				return err
 			}
-			field.SetUint(x)
-		case "uint16":
-			x, err := strconv.ParseUint(z(paramValues[0]), 10, 16)
+			s = append(s, x)
+		}
+		field.Set(reflect.ValueOf(s))
+	case "[]uint":
+		s := make([]uint, 0, len(paramValues))
+		for _, value := range paramValues {
+			x, err := strconv.ParseFloat(z(value), 0)
 			if err != nil {
 				return err
 			}
-			field.SetUint(x)
-		case "uint32":
-			x, err := strconv.ParseUint(z(paramValues[0]), 10, 32)
+			s = append(s, uint(x))
+		}
+		field.Set(reflect.ValueOf(s))
+	case "[]uint8":
+		s := make([]uint8, 0, len(paramValues))
+		for _, value := range paramValues {
+			x, err := strconv.ParseFloat(z(value), 8)
 			if err != nil {
 				return err
 			}
-			field.SetUint(x)
-		case "uint64":
-			x, err := strconv.ParseUint(z(paramValues[0]), 10, 64)
+			s = append(s, uint8(x))
+		}
+		field.Set(reflect.ValueOf(s))
+	case "[]uint16":
+		s := make([]uint16, 0, len(paramValues))
+		for _, value := range paramValues {
+			x, err := strconv.ParseFloat(z(value), 16)
 			if err != nil {
 				return err
 			}
-			field.SetUint(x)
-		case "[]bool":
-			s := make([]bool, 0, len(paramValues))
-			for _, value := range paramValues {
-				str := strings.ToLower(value)
-				b := str == "1" || str == "true" || str == "yes"
-				s = append(s, b)
-			}
-			field.Set(reflect.ValueOf(s))
-		case "[]float32":
-			s := make([]float32, 0, len(paramValues))
-			for _, value := range paramValues {
-				x, err := strconv.ParseFloat(z(value), 32)
-				if err != nil {
-					return err
-				}
-				s = append(s, float32(x))
-			}
-			field.Set(reflect.ValueOf(s))
-		case "[]float64":
-			s := make([]float64, 0, len(paramValues))
-			for _, value := range paramValues {
-				x, err := strconv.ParseFloat(z(value), 64)
-				if err != nil {
-					return err
-				}
-				s = append(s, x)
-			}
-			field.Set(reflect.ValueOf(s))
-		case "[]int":
-			s := make([]int, 0, len(paramValues))
-			for _, value := range paramValues {
-				x, err := strconv.ParseFloat(z(value), 0)
-				if err != nil {
-					return err
-				}
-				s = append(s, int(x))
-			}
-			field.Set(reflect.ValueOf(s))
-		case "[]int8":
-			s := make([]int8, 0, len(paramValues))
-			for _, value := range paramValues {
-				x, err := strconv.ParseFloat(z(value), 8)
-				if err != nil {
-					return err
-				}
-				s = append(s, int8(x))
-			}
-			field.Set(reflect.ValueOf(s))
-		case "[]int16":
-			s := make([]int16, 0, len(paramValues))
-			for _, value := range paramValues {
-				x, err := strconv.ParseFloat(z(value), 16)
-				if err != nil {
-					return err
-				}
-				s = append(s, int16(x))
-			}
-			field.Set(reflect.ValueOf(s))
-		case "[]int32":
-			s := make([]int32, 0, len(paramValues))
-			for _, value := range paramValues {
-				x, err := strconv.ParseFloat(z(value), 32)
-				if err != nil {
-					return err
-				}
-				s = append(s, int32(x))
-			}
-			field.Set(reflect.ValueOf(s))
-		case "[]int64":
-			s := make([]int64, 0, len(paramValues))
-			for _, value := range paramValues {
-				x, err := strconv.ParseFloat(z(value), 64)
-				if err != nil {
-					return err
-				}
-				s = append(s, int64(x))
-			}
-			field.Set(reflect.ValueOf(s))
-		case "[]string":
-			field.Set(reflect.ValueOf(paramValues))
-		case "[]uint":
-			s := make([]uint, 0, len(paramValues))
-			for _, value := range paramValues {
-				x, err := strconv.ParseFloat(z(value), 0)
-				if err != nil {
-					return err
-				}
-				s = append(s, uint(x))
-			}
-			field.Set(reflect.ValueOf(s))
-		case "[]uint8":
-			s := make([]uint8, 0, len(paramValues))
-			for _, value := range paramValues {
-				x, err := strconv.ParseFloat(z(value), 8)
-				if err != nil {
-					return err
-				}
-				s = append(s, uint8(x))
-			}
-			field.Set(reflect.ValueOf(s))
-		case "[]uint16":
-			s := make([]uint16, 0, len(paramValues))
-			for _, value := range paramValues {
-				x, err := strconv.ParseFloat(z(value), 16)
-				if err != nil {
-					return err
-				}
-				s = append(s, uint16(x))
-			}
-			field.Set(reflect.ValueOf(s))
-		case "[]uint32":
-			s := make([]uint32, 0, len(paramValues))
-			for _, value := range paramValues {
-				x, err := strconv.ParseFloat(z(value), 32)
-				if err != nil {
-					return err
-				}
-				s = append(s, uint32(x))
+			s = append(s, uint16(x))
+		}
+		field.Set(reflect.ValueOf(s))
+	case "[]uint32":
+		s := make([]uint32, 0, len(paramValues))
+		for _, value := range paramValues {
+			x, err := strconv.ParseFloat(z(value), 32)
+			if err != nil {
+				return err
 			}
-			field.Set(reflect.ValueOf(s))
-		case "[]uint64":
-			s := make([]uint64, 0, len(paramValues))
-			for _, value := range paramValues {
-				x, err := strconv.ParseFloat(z(value), 64)
-				if err != nil {
-					return err
-				}
-				s = append(s, uint64(x))
+			s = append(s, uint32(x))
+		}
+		field.Set(reflect.ValueOf(s))
+	case "[]uint64":
+		s := make([]uint64, 0, len(paramValues))
+		for _, value := range paramValues {
+			x, err := strconv.ParseFloat(z(value), 64)
+			if err != nil {
+				return err
 			}
-			field.Set(reflect.ValueOf(s))
-		default:
-			return errors.New("unsupported field type " + field.Type().String())
+			s = append(s, uint64(x))
 		}
+		field.Set(reflect.ValueOf(s))
+	default:
+		return errors.New("unsupported field type " + field.Type().String())
 	}
+	return nil
+}
 
-	if p.AfterParse != nil {
-		return p.AfterParse(dest)
+// parseStructSlice populates field, a slice of structs, with the indexed
+// parameters in form whose names start with paramName.
+func (p *Parser) parseStructSlice(field reflect.Value, paramName string, form map[string][]string) error {
+	groups := make(map[int]map[string][]string)
+
+	for key, values := range form {
+		index, name, ok := splitIndexedName(key, paramName)
+		if !ok {
+			continue
+		}
+
+		if groups[index] == nil {
+			groups[index] = make(map[string][]string)
+		}
+		groups[index][name] = values
+	}
+
+	if len(groups) == 0 {
+		return nil
 	}
+
+	indexes := make([]int, 0, len(groups))
+	for index := range groups {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	s := reflect.MakeSlice(field.Type(), 0, len(indexes))
+	for _, index := range indexes {
+		group := groups[index]
+		element := reflect.New(field.Type().Elem()).Elem()
+
+		param := func(name string) []string {
+			return group[name]
+		}
+
+		if err := p.parseStruct(element, param, group); err != nil {
+			return err
+		}
+		s = reflect.Append(s, element)
+	}
+	field.Set(s)
 	return nil
 }
 
+// isStructSlice returns whether t is a slice of structs that are populated
+// from indexed parameters, see parseStructSlice. Structs without exported
+// fields, e.g. time.Time, are not populated that way.
+func isStructSlice(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Struct || t.Elem() == timeType {
+		return false
+	}
+
+	for i, j := 0, t.Elem().NumField(); i < j; i++ {
+		if t.Elem().Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// splitIndexedName splits a parameter name of the form “prefix[index].name”
+// into index and name. ok is false if key does not have this form.
+func splitIndexedName(key, prefix string) (index int, name string, ok bool) {
+	if !strings.HasPrefix(key, prefix+"[") {
+		return 0, "", false
+	}
+	rest := key[len(prefix)+1:]
+
+	end := strings.Index(rest, "].")
+	if end < 1 {
+		return 0, "", false
+	}
+
+	index, err := strconv.Atoi(rest[:end])
+	if err != nil || index < 0 {
+		return 0, "", false
+	}
+
+	name = rest[end+2:]
+	if name == "" {
+		return 0, "", false
+	}
+	return index, name, true
+}
+
 // param returns the parameter that matches the provided name. It checks
 // httprouter, POST, PUT, GET, etc., parameters for a match.
-func (p *Parser) param(name string) []string {
+func (p *Parser) param(name string, form map[string][]string) []string {
 	if len(p.routerParams) > 0 {
 		for _, routeParam := range p.routerParams {
 			if routeParam.Key == name {
@@ -298,15 +540,35 @@ func (p *Parser) param(name string) []string {
 		}
 	}
 
-	if p.request != nil {
-		if values, ok := p.request.Form[name]; ok {
-			return values
-		}
+	if values, ok := form[name]; ok {
+		return values
 	}
 
 	return nil
 }
 
+// parseBool converts value to a boolean according to p.TrueValues,
+// p.FalseValues and p.StrictBools.
+func (p *Parser) parseBool(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	for _, trueValue := range p.TrueValues {
+		if strings.EqualFold(value, trueValue) {
+			return true, nil
+		}
+	}
+	if p.StrictBools {
+		for _, falseValue := range p.FalseValues {
+			if strings.EqualFold(value, falseValue) {
+				return false, nil
+			}
+		}
+		return false, errors.New("invalid boolean value " + strconv.Quote(value))
+	}
+	return false, nil
+}
+
 func z(value string) string {
 	if value == "" {
 		return "0"
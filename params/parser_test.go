@@ -5,9 +5,12 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ChristianSiegert/go-packages/params"
+	"github.com/julienschmidt/httprouter"
 )
 
 // Dest1 is a destination for writing parsed URL values into.
@@ -54,10 +57,34 @@ type Dest1 struct {
 	Suint64  []uint64
 }
 
+// Dest2 is a destination for writing durations into.
+type Dest2 struct {
+	Duration  time.Duration
+	Sduration []time.Duration
+}
+
 type Dest3 struct {
 	Map map[string]string
 }
 
+// Dest4 is a destination for writing indexed URL values into.
+type Dest4 struct {
+	Items []LineItem `param:"items"`
+	Title string
+}
+
+// LineItem is an element of Dest4.Items.
+type LineItem struct {
+	Name string `param:"name"`
+	Qty  int    `param:"qty"`
+	Tags []Tag  `param:"tags"`
+}
+
+// Tag is an element of LineItem.Tags.
+type Tag struct {
+	Label string `param:"label"`
+}
+
 // methods are HTTP methods the parser must support when parsing URL values.
 var methods = []string{
 	http.MethodConnect,
@@ -252,6 +279,56 @@ func TestParser_Parse(t *testing.T) {
 				Suint64:  []uint64{0, 52, 53},
 			},
 		},
+		// Test parsing indexed parameters into slice of structs
+		{
+			inputDest: &Dest4{},
+			inputParams: url.Values{
+				"Title":                  []string{"order"},
+				"items[5].name":          []string{"baz"},
+				"items[0].name":          []string{"foo"},
+				"items[0].qty":           []string{"2"},
+				"items[0].tags[0].label": []string{"red"},
+				"items[2].name":          []string{"bar"},
+				"items[x].name":          []string{"ignored"},
+				"items[1]":               []string{"ignored"},
+			},
+			expected: &Dest4{
+				Items: []LineItem{
+					{Name: "foo", Qty: 2, Tags: []Tag{{Label: "red"}}},
+					{Name: "bar"},
+					{Name: "baz"},
+				},
+				Title: "order",
+			},
+		},
+		// Test parsing invalid indexed parameter
+		{
+			inputDest:   &Dest4{},
+			inputParams: url.Values{"items[0].qty": []string{"foo"}},
+			expectErr:   true,
+		},
+		// Test durations
+		{
+			inputDest: &Dest2{},
+			inputParams: url.Values{
+				"Duration":  []string{"1m30s"},
+				"Sduration": []string{"30s", "", "2h"},
+			},
+			expected: &Dest2{
+				Duration:  90 * time.Second,
+				Sduration: []time.Duration{30 * time.Second, 0, 2 * time.Hour},
+			},
+		},
+		{
+			inputDest:   &Dest2{},
+			inputParams: url.Values{"Duration": []string{""}},
+			expected:    &Dest2{},
+		},
+		{
+			inputDest:   &Dest2{},
+			inputParams: url.Values{"Duration": []string{"30"}},
+			expectErr:   true,
+		},
 		// Test passing unsupported type
 		{
 			inputDest:   &Dest3{},
@@ -306,3 +383,154 @@ func TestParser_Parse(t *testing.T) {
 		}
 	}
 }
+
+func TestParser_ParseQuery(t *testing.T) {
+	type Dest struct {
+		ID     string
+		Query  string
+		Status string
+	}
+
+	body := strings.NewReader("Query=body&Status=body")
+	request := httptest.NewRequest(http.MethodPost, "/?Query=url", body)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	parser, err := params.NewParser(request, httprouter.Params{{Key: "ID", Value: "router"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dest := &Dest{}
+	expected := &Dest{ID: "router", Query: "url"}
+
+	if err := parser.ParseQuery(dest); err != nil {
+		t.Fatalf("ParseQuery failed: unexpected error: %s", err)
+	} else if !reflect.DeepEqual(dest, expected) {
+		t.Fatalf("ParseQuery failed:\nexpected %#v\n\ngot %#v", expected, dest)
+	}
+}
+
+func TestParser_ParseWithSchema(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/?limit=10&ratio=0.5&active=yes&name=foo", nil)
+
+	parser, err := params.NewParser(request, httprouter.Params{{Key: "id", Value: "7"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := map[string]reflect.Kind{
+		"active":  reflect.Bool,
+		"id":      reflect.Uint64,
+		"limit":   reflect.Int,
+		"missing": reflect.String,
+		"name":    reflect.String,
+		"ratio":   reflect.Float64,
+	}
+	expected := map[string]interface{}{
+		"active": true,
+		"id":     uint64(7),
+		"limit":  10,
+		"name":   "foo",
+		"ratio":  0.5,
+	}
+
+	if result, err := parser.ParseWithSchema(schema); err != nil {
+		t.Fatalf("ParseWithSchema failed: unexpected error: %s", err)
+	} else if !reflect.DeepEqual(result, expected) {
+		t.Errorf("ParseWithSchema failed:\nexpected %#v\n\ngot %#v", expected, result)
+	}
+
+	if _, err := parser.ParseWithSchema(map[string]reflect.Kind{"name": reflect.Int}); err == nil {
+		t.Errorf("Expected error for invalid number.")
+	}
+	if _, err := parser.ParseWithSchema(map[string]reflect.Kind{"name": reflect.Map}); err == nil {
+		t.Errorf("Expected error for unsupported kind.")
+	}
+}
+
+func TestParser_Parse_boolValues(t *testing.T) {
+	type dest struct {
+		Checked  bool
+		Custom   bool
+		Flags    []bool
+		Off      bool
+		Unknown  bool
+		Unposted bool
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/?Checked=on&Custom=JA&Flags=ON&Flags=off&Flags=&Off=off&Unknown=maybe", nil)
+
+	parser, err := params.NewParser(request, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result dest
+	if err := parser.Parse(&result); err != nil {
+		t.Fatalf("Parse failed: unexpected error: %s", err)
+	} else if expected := (dest{Checked: true, Flags: []bool{true, false, false}}); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+
+	parser.TrueValues = append(parser.TrueValues, "ja")
+	result = dest{}
+	if err := parser.Parse(&result); err != nil {
+		t.Fatalf("Parse failed: unexpected error: %s", err)
+	} else if !result.Custom {
+		t.Errorf("Expected custom true value to be true.")
+	}
+
+	parser.StrictBools = true
+	if err := parser.Parse(&dest{}); err == nil {
+		t.Errorf("Expected error for unknown boolean value in strict mode.")
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/?Checked=on&Off=OFF&Flags=&Flags=no", nil)
+	if parser, err = params.NewParser(request, nil); err != nil {
+		t.Fatal(err)
+	}
+	parser.StrictBools = true
+
+	result = dest{}
+	if err := parser.Parse(&result); err != nil {
+		t.Fatalf("Parse failed: unexpected error: %s", err)
+	} else if expected := (dest{Checked: true, Flags: []bool{false, false}}); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestParser_Parse_structsWithUnexportedFields(t *testing.T) {
+	type item struct {
+		Name  string
+		count int
+	}
+
+	type dest struct {
+		Dates []time.Time
+		Items []item
+		Times []time.Time
+		count int
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/?Dates[0].wall=1&Dates[0].ext=2&Items[0].Name=foo&Items[0].count=3&count=4", nil)
+
+	parser, err := params.NewParser(request, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result dest
+	if err := parser.Parse(&result); err != nil {
+		t.Fatalf("Parse failed: unexpected error: %s", err)
+	} else if expected := (dest{Items: []item{{Name: "foo"}}}); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/?Times=2020-01-01", nil)
+	if parser, err = params.NewParser(request, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.Parse(&dest{}); err == nil {
+		t.Errorf("Expected error for unsupported field type []time.Time.")
+	}
+}
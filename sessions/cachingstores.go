@@ -0,0 +1,263 @@
+package sessions
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CachingStore wraps a Store and keeps recently accessed sessions in memory,
+// so getting the same session again within TTL does not hit the wrapped store.
+// Save and Delete remove the session from the cache before passing the call
+// on, so changes made through the CachingStore are seen by the next Get.
+// Changes made elsewhere, e.g. by another server using the same database, are
+// only seen after TTL, so TTL should be short. A CachingStore is safe for
+// concurrent use.
+//
+// Sessions returned by a CachingStore belong to it, i.e. their Save and Delete
+// methods call the CachingStore. Each call of Get returns a new copy of the
+// cached session, so requests do not share session objects.
+type CachingStore struct {
+	// MaxSessions is the maximum number of cached sessions. If the cache is
+	// full, the least recently used session is removed. 0 means unlimited.
+	MaxSessions int
+
+	// SessionID returns the ID of the session requested by request, e.g. the
+	// value of the session cookie, or an empty string if there is none.
+	SessionID func(request *http.Request) string
+
+	// Store is the wrapped store.
+	Store Store
+
+	// TTL is the duration for which a session is served from the cache.
+	TTL time.Duration
+
+	entries map[string]*list.Element
+	lru     *list.List // Front is the most recently used entry.
+	mutex   sync.Mutex
+
+	// clock is used instead of time.Now for expiring cached sessions if it
+	// is not nil, so tests can control when sessions expire.
+	clock func() time.Time
+}
+
+// cachedSession is a snapshot of a session.
+type cachedSession struct {
+	dateCreated time.Time
+	expires     time.Time
+	flashes     []Flash
+	id          string
+	isDirty     bool
+	isStored    bool
+	values      map[string]string
+}
+
+// NewCachingStore returns a new instance of CachingStore that wraps store.
+// sessionID must return the ID of the session requested by a request.
+func NewCachingStore(store Store, sessionID func(request *http.Request) string, maxSessions int, ttl time.Duration) *CachingStore {
+	return &CachingStore{
+		MaxSessions: maxSessions,
+		SessionID:   sessionID,
+		Store:       store,
+		TTL:         ttl,
+		entries:     make(map[string]*list.Element),
+		lru:         list.New(),
+	}
+}
+
+// Delete removes the session from the cache and deletes it from the wrapped
+// store.
+func (c *CachingStore) Delete(writer http.ResponseWriter, sessionID string) error {
+	c.remove(sessionID)
+	return c.Store.Delete(writer, sessionID)
+}
+
+// DeleteExpired removes sessions that were created before before from the
+// cache and deletes them from the wrapped store.
+func (c *CachingStore) DeleteExpired(before time.Time) (int, error) {
+	c.mutex.Lock()
+	for id, element := range c.entries {
+		if element.Value.(*cachedSession).dateCreated.Before(before) {
+			c.lru.Remove(element)
+			delete(c.entries, id)
+		}
+	}
+	c.mutex.Unlock()
+
+	return c.Store.DeleteExpired(before)
+}
+
+// DeleteMulti empties the cache and deletes the sessions that match filter
+// from the wrapped store.
+func (c *CachingStore) DeleteMulti(filter *Filter) error {
+	c.mutex.Lock()
+	c.entries = make(map[string]*list.Element)
+	c.lru = list.New()
+	c.mutex.Unlock()
+
+	return c.Store.DeleteMulti(filter)
+}
+
+// Get returns the requested session from the cache. If it is not cached or
+// expired, it gets the session from the wrapped store and caches it if it is
+// stored there and unchanged.
+func (c *CachingStore) Get(writer http.ResponseWriter, request *http.Request) (Session, error) {
+	if id := c.sessionID(request); id != "" {
+		if cached := c.get(id); cached != nil {
+			return c.restore(cached), nil
+		}
+	}
+
+	session, err := c.Store.Get(writer, request)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := c.snapshot(session)
+	if cached.isStored && !cached.isDirty {
+		c.add(cached)
+	}
+	return c.restore(cached), nil
+}
+
+// GetMulti gets the sessions that match filter from the wrapped store. The
+// sessions are not cached.
+func (c *CachingStore) GetMulti(filter *Filter) ([]Session, error) {
+	sessions, err := c.Store.GetMulti(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, session := range sessions {
+		sessions[i] = c.restore(c.snapshot(session))
+	}
+	return sessions, nil
+}
+
+// Save removes the session from the cache and saves it to the wrapped store.
+func (c *CachingStore) Save(writer http.ResponseWriter, session Session) error {
+	c.remove(session.ID())
+	return c.Store.Save(writer, session)
+}
+
+// SaveMulti removes the sessions from the cache and saves them to the wrapped
+// store.
+func (c *CachingStore) SaveMulti(sessions []Session) error {
+	for _, session := range sessions {
+		c.remove(session.ID())
+	}
+	return c.Store.SaveMulti(sessions)
+}
+
+// add adds cached to the cache. If the cache is full, the least recently used
+// session is removed.
+func (c *CachingStore) add(cached *cachedSession) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	cached.expires = c.now().Add(c.TTL)
+
+	if c.entries == nil {
+		c.entries = make(map[string]*list.Element)
+		c.lru = list.New()
+	}
+
+	if element, ok := c.entries[cached.id]; ok {
+		element.Value = cached
+		c.lru.MoveToFront(element)
+		return
+	}
+
+	c.entries[cached.id] = c.lru.PushFront(cached)
+
+	for c.MaxSessions > 0 && c.lru.Len() > c.MaxSessions {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedSession).id)
+	}
+}
+
+// get returns the cached session with the provided ID, or nil if there is
+// none or it expired.
+func (c *CachingStore) get(sessionID string) *cachedSession {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[sessionID]
+	if !ok {
+		return nil
+	}
+
+	cached := element.Value.(*cachedSession)
+	if !c.now().Before(cached.expires) {
+		c.lru.Remove(element)
+		delete(c.entries, sessionID)
+		return nil
+	}
+
+	c.lru.MoveToFront(element)
+	return cached
+}
+
+// now returns the time against which cached sessions expire.
+func (c *CachingStore) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock()
+}
+
+// remove removes the session with the provided ID from the cache.
+func (c *CachingStore) remove(sessionID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[sessionID]; ok {
+		c.lru.Remove(element)
+		delete(c.entries, sessionID)
+	}
+}
+
+// restore returns a new session that belongs to c and has the data of cached.
+func (c *CachingStore) restore(cached *cachedSession) Session {
+	session := NewSession(c, cached.id)
+	session.SetDateCreated(cached.dateCreated)
+
+	for _, flash := range cached.flashes {
+		session.Flashes().Add(NewFlash(flash.Message(), flash.Type()))
+	}
+	session.Values().SetAll(cached.values)
+	session.SetIsStored(cached.isStored)
+	session.SetIsDirty(cached.isDirty)
+	return session
+}
+
+// sessionID returns the ID of the session requested by request, see
+// SessionID. If c.SessionID is nil, it returns an empty string.
+func (c *CachingStore) sessionID(request *http.Request) string {
+	if c.SessionID == nil {
+		return ""
+	}
+	return c.SessionID(request)
+}
+
+// snapshot returns a copy of session’s data that is not changed by changes to
+// session.
+func (c *CachingStore) snapshot(session Session) *cachedSession {
+	cached := &cachedSession{
+		dateCreated: session.DateCreated(),
+		id:          session.ID(),
+		isDirty:     session.IsDirty(),
+		isStored:    session.IsStored(),
+		values:      make(map[string]string),
+	}
+
+	for _, flash := range session.Flashes().GetAll() {
+		cached.flashes = append(cached.flashes, NewFlash(flash.Message(), flash.Type()))
+	}
+	for key, value := range session.Values().GetAll() {
+		cached.values[key] = value
+	}
+	return cached
+}
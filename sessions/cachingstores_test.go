@@ -0,0 +1,167 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// memoryStore is a minimal Store that counts calls of Get.
+type memoryStore struct {
+	gets     int
+	sessions map[string]map[string]string
+}
+
+func (m *memoryStore) Delete(writer http.ResponseWriter, sessionID string) error {
+	delete(m.sessions, sessionID)
+	return nil
+}
+
+func (m *memoryStore) DeleteExpired(before time.Time) (int, error) {
+	return 0, nil
+}
+
+func (m *memoryStore) DeleteMulti(filter *Filter) error {
+	if len(filter.IDs) == 0 {
+		m.sessions = make(map[string]map[string]string)
+	}
+	for _, id := range filter.IDs {
+		delete(m.sessions, id)
+	}
+	return nil
+}
+
+func (m *memoryStore) Get(writer http.ResponseWriter, request *http.Request) (Session, error) {
+	m.gets++
+	id := request.Header.Get("Session-ID")
+	session := NewSession(m, id)
+
+	if values, ok := m.sessions[id]; ok {
+		session.Values().SetAll(values)
+		session.SetIsStored(true)
+		session.SetIsDirty(false)
+	}
+	return session, nil
+}
+
+func (m *memoryStore) GetMulti(filter *Filter) ([]Session, error) {
+	return nil, nil
+}
+
+func (m *memoryStore) Save(writer http.ResponseWriter, session Session) error {
+	values := make(map[string]string)
+	for key, value := range session.Values().GetAll() {
+		values[key] = value
+	}
+	m.sessions[session.ID()] = values
+	session.SetIsStored(true)
+	session.SetIsDirty(false)
+	return nil
+}
+
+func (m *memoryStore) SaveMulti(sessions []Session) error {
+	for _, session := range sessions {
+		m.Save(nil, session)
+	}
+	return nil
+}
+
+func TestCachingStore(t *testing.T) {
+	store := &memoryStore{sessions: map[string]map[string]string{
+		"a": {"name": "Jane"},
+		"b": {},
+		"c": {},
+	}}
+
+	cache := NewCachingStore(store, func(request *http.Request) string {
+		return request.Header.Get("Session-ID")
+	}, 2, time.Minute)
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.clock = func() time.Time { return now }
+
+	get := func(id string) Session {
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("Session-ID", id)
+
+		session, err := cache.Get(httptest.NewRecorder(), request)
+		if err != nil {
+			t.Fatalf("Get failed unexpectedly: %s", err)
+		}
+		return session
+	}
+
+	session := get("a")
+	if name := session.Values().Get("name"); name != "Jane" {
+		t.Errorf("Expected name %q, got %q.", "Jane", name)
+	} else if !session.IsStored() || session.IsDirty() {
+		t.Errorf("Expected stored, clean session.")
+	} else if session.Store() != cache {
+		t.Errorf("Expected session to belong to the caching store.")
+	}
+
+	// Changes to a returned session do not change the cached session.
+	session.Values().Set("name", "John")
+	if name := get("a").Values().Get("name"); name != "Jane" || store.gets != 1 {
+		t.Errorf("Expected cached name %q and 1 get, got %q and %d gets.", "Jane", name, store.gets)
+	}
+
+	// Saving removes the session from the cache.
+	if err := session.Save(httptest.NewRecorder()); err != nil {
+		t.Fatalf("Save failed unexpectedly: %s", err)
+	}
+	if name := get("a").Values().Get("name"); name != "John" || store.gets != 2 {
+		t.Errorf("Expected name %q and 2 gets, got %q and %d gets.", "John", name, store.gets)
+	}
+
+	// The least recently used session is removed if the cache is full.
+	get("b")
+	get("c")
+	get("a")
+	if store.gets != 5 {
+		t.Errorf("Expected 5 gets, got %d.", store.gets)
+	}
+
+	// Sessions expire after TTL.
+	now = now.Add(2 * time.Minute)
+	get("c")
+	if store.gets != 6 {
+		t.Errorf("Expected 6 gets, got %d.", store.gets)
+	}
+
+	// New sessions are not cached.
+	get("d")
+	get("d")
+	if store.gets != 8 {
+		t.Errorf("Expected 8 gets, got %d.", store.gets)
+	}
+}
+
+func TestCachingStore_zeroValue(t *testing.T) {
+	store := &CachingStore{
+		SessionID: func(request *http.Request) string { return request.Header.Get("Session-ID") },
+		Store:     &memoryStore{sessions: map[string]map[string]string{"a": {"foo": "bar"}}},
+		TTL:       time.Minute,
+	}
+
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Set("Session-ID", "a")
+
+	for i := 0; i < 2; i++ {
+		if session, err := store.Get(nil, request); err != nil {
+			t.Fatalf("Get failed unexpectedly: %s", err)
+		} else if session.Values().Get("foo") != "bar" {
+			t.Errorf("Expected value %q, got %q", "bar", session.Values().Get("foo"))
+		}
+	}
+	if gets := store.Store.(*memoryStore).gets; gets != 1 {
+		t.Errorf("Expected 1 Get of wrapped store, got %d", gets)
+	}
+
+	if err := (&CachingStore{Store: store.Store}).DeleteMulti(&Filter{}); err != nil {
+		t.Errorf("DeleteMulti failed unexpectedly: %s", err)
+	}
+	if _, err := (&CachingStore{Store: store.Store}).Get(nil, request); err != nil {
+		t.Errorf("Get without SessionID failed unexpectedly: %s", err)
+	}
+}
@@ -0,0 +1,33 @@
+package sessions
+
+// KeyIPAddress and KeyUserAgent are the keys under which SetClient stores the
+// IP address and user agent of the client that created a session.
+var (
+	KeyIPAddress = "client.ip"
+	KeyUserAgent = "client.user-agent"
+)
+
+// IPAddress returns the IP address of the client that created the session.
+func (s *session) IPAddress() string {
+	return s.values.Get(KeyIPAddress)
+}
+
+// SetClient sets the IP address and user agent of the client that created the
+// session. Empty arguments remove the respective value.
+func (s *session) SetClient(ipAddress, userAgent string) {
+	for key, value := range map[string]string{
+		KeyIPAddress: ipAddress,
+		KeyUserAgent: userAgent,
+	} {
+		if value == "" {
+			s.values.Remove(key)
+		} else {
+			s.values.Set(key, value)
+		}
+	}
+}
+
+// UserAgent returns the user agent of the client that created the session.
+func (s *session) UserAgent() string {
+	return s.values.Get(KeyUserAgent)
+}
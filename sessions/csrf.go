@@ -0,0 +1,44 @@
+package sessions
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"io"
+)
+
+// KeyCSRFToken is the key under which CSRFToken stores the session’s CSRF
+// token in the session’s values.
+var KeyCSRFToken = "csrf.token"
+
+// csrfTokenStrength is the number of random bytes of a CSRF token.
+const csrfTokenStrength = 32
+
+// CSRFToken returns the session’s token for protecting forms against
+// cross-site request forgery. If the session has no token yet, a token is
+// generated and stored in the session’s values.
+func (s *session) CSRFToken() (string, error) {
+	if token := s.values.Get(KeyCSRFToken); token != "" {
+		return token, nil
+	}
+
+	b := make([]byte, csrfTokenStrength)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+
+	token := hex.EncodeToString(b)
+	s.values.Set(KeyCSRFToken, token)
+	return token, nil
+}
+
+// VerifyCSRFToken returns whether token, e.g. submitted with a form, equals the
+// CSRF token stored in session. If session has no CSRF token, VerifyCSRFToken
+// returns false. Tokens are compared in constant time.
+func VerifyCSRFToken(session Session, token string) bool {
+	expected := session.Values().Get(KeyCSRFToken)
+	if expected == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(token)) == 1
+}
@@ -0,0 +1,36 @@
+package sessions
+
+import "testing"
+
+func TestSession_CSRFToken(t *testing.T) {
+	session := NewSession(nil, "session123")
+
+	if VerifyCSRFToken(session, "") {
+		t.Errorf("Expected empty token to be rejected without stored token.")
+	}
+
+	token, err := session.CSRFToken()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if len(token) != 2*csrfTokenStrength {
+		t.Errorf("Expected token of length %d, got %q", 2*csrfTokenStrength, token)
+	} else if !session.IsDirty() {
+		t.Errorf("Expected session to be dirty after generating token.")
+	}
+
+	if again, err := session.CSRFToken(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if again != token {
+		t.Errorf("Expected token %q to persist, got %q", token, again)
+	}
+
+	if !VerifyCSRFToken(session, token) {
+		t.Errorf("Expected token to be valid.")
+	} else if VerifyCSRFToken(session, token[1:]) || VerifyCSRFToken(session, "") {
+		t.Errorf("Expected other tokens to be invalid.")
+	}
+
+	if other, _ := NewSession(nil, "session456").CSRFToken(); other == token {
+		t.Errorf("Expected sessions to have different tokens.")
+	}
+}
@@ -11,6 +11,12 @@ type Flashes interface {
 	// first given flashType is used.
 	AddNew(message string, flashType ...string) Flash
 
+	// ByType returns all flashes of the provided type.
+	ByType(flashType string) []Flash
+
+	// Count returns the number of flashes.
+	Count() int
+
 	// GetAll returns all flashes.
 	GetAll() []Flash
 
@@ -46,6 +52,22 @@ func (f *flashes) AddNew(message string, flashType ...string) Flash {
 	return flash
 }
 
+// ByType returns all flashes of the provided type.
+func (f *flashes) ByType(flashType string) []Flash {
+	var ff []Flash
+	for _, flash := range *f {
+		if flash.Type() == flashType {
+			ff = append(ff, flash)
+		}
+	}
+	return ff
+}
+
+// Count returns the number of flashes.
+func (f *flashes) Count() int {
+	return len(*f)
+}
+
 // GetAll returns all flashes.
 func (f *flashes) GetAll() []Flash {
 	return []Flash(*f)
@@ -70,6 +92,39 @@ func (f *flashes) RemoveAll() {
 	*f = flashes{}
 }
 
+// trackedFlashes wraps Flashes to mark the session as dirty when flashes are
+// added or removed.
+type trackedFlashes struct {
+	Flashes
+	session *session
+}
+
+// Add adds flashes.
+func (t *trackedFlashes) Add(flashes ...Flash) {
+	t.Flashes.Add(flashes...)
+	t.session.isDirty = true
+}
+
+// AddNew creates a new Flash and adds it. flashType is optional. Only the
+// first given flashType is used.
+func (t *trackedFlashes) AddNew(message string, flashType ...string) Flash {
+	flash := t.Flashes.AddNew(message, flashType...)
+	t.session.isDirty = true
+	return flash
+}
+
+// Remove removes flashes.
+func (t *trackedFlashes) Remove(flashes ...Flash) {
+	t.Flashes.Remove(flashes...)
+	t.session.isDirty = true
+}
+
+// RemoveAll removes all flashes.
+func (t *trackedFlashes) RemoveAll() {
+	t.Flashes.RemoveAll()
+	t.session.isDirty = true
+}
+
 // FlashesFromJSON JSON decodes an array of Flash objects. The result is useful
 // as input for Flashes.Add.
 func FlashesFromJSON(data []byte) ([]Flash, error) {
@@ -31,6 +31,32 @@ func TestFlashes_AddNew(t *testing.T) {
 	}
 }
 
+func TestFlashes_ByType(t *testing.T) {
+	flashes := NewFlashes()
+	flashC := flashes.AddNew("c", "error")
+	flashes.AddNew("d", "info")
+	flashE := flashes.AddNew("e", "error")
+	expected := []Flash{flashC, flashE}
+
+	if result := flashes.ByType("error"); !reflect.DeepEqual(expected, result) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	} else if result := flashes.ByType("warning"); len(result) != 0 {
+		t.Errorf("Expected no flashes, got %v", result)
+	}
+}
+
+func TestFlashes_Count(t *testing.T) {
+	flashes := NewFlashes()
+	if result := flashes.Count(); result != 0 {
+		t.Errorf("Expected 0, got %d", result)
+	}
+
+	flashes.Add(flashA, flashB)
+	if result := flashes.Count(); result != 2 {
+		t.Errorf("Expected 2, got %d", result)
+	}
+}
+
 func TestFlashes_Remove(t *testing.T) {
 	flashes := NewFlashes()
 	flashA := flashes.AddNew("a")
@@ -0,0 +1,46 @@
+package sessions
+
+import "errors"
+
+// MigrateAnonymous copies the values and flashes of from, e.g. the session of
+// an anonymous user with items in their cart, into the session, and deletes
+// from from its store. Values stored under KeyCSRFToken, KeyImpersonators,
+// KeyIPAddress, KeyUserAgent and KeyUserID are not copied. Values of from
+// replace values of the session with the same key.
+func (s *session) MigrateAnonymous(from Session) error {
+	if from.ID() == s.ID() {
+		return errors.New("sessions: cannot migrate session into itself")
+	}
+
+	excluded := map[string]bool{
+		KeyCSRFToken:     true,
+		KeyImpersonators: true,
+		KeyIPAddress:     true,
+		KeyUserAgent:     true,
+		KeyUserID:        true,
+	}
+
+	values := make(map[string]string)
+	for key, value := range from.Values().GetAll() {
+		if !excluded[key] {
+			values[key] = value
+		}
+	}
+	s.values.SetAll(values)
+
+	for _, flash := range from.Flashes().GetAll() {
+		s.flashes.Add(NewFlash(flash.Message(), flash.Type()))
+	}
+
+	if !from.IsStored() {
+		return nil
+	}
+
+	// DeleteMulti does not touch the session cookie, which the session saved
+	// afterwards uses.
+	if err := from.Store().DeleteMulti(&Filter{IDs: []string{from.ID()}}); err != nil {
+		return err
+	}
+	from.SetIsStored(false)
+	return nil
+}
@@ -0,0 +1,39 @@
+package sessions
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSession_MigrateAnonymous(t *testing.T) {
+	store := &memoryStore{sessions: make(map[string]map[string]string)}
+
+	anonymous := NewSession(store, "anonymous")
+	anonymous.Values().Set("cart", "1,2")
+	anonymous.Values().Set(KeyCSRFToken, "anonymous-token")
+	anonymous.Flashes().AddNew("Added to cart")
+	if err := anonymous.Save(httptest.NewRecorder()); err != nil {
+		t.Fatalf("Save failed: %s", err)
+	}
+
+	session := NewSession(store, "authenticated")
+	session.SetUserID("user1")
+
+	if err := session.MigrateAnonymous(anonymous); err != nil {
+		t.Fatalf("MigrateAnonymous failed: %s", err)
+	}
+
+	expected := map[string]string{"cart": "1,2", KeyUserID: "user1"}
+	if values := session.Values().GetAll(); !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected values %v, got %v", expected, values)
+	} else if flashes := session.Flashes().GetAll(); len(flashes) != 1 || flashes[0].Message() != "Added to cart" {
+		t.Errorf("Expected flash to be copied, got %v", flashes)
+	} else if _, ok := store.sessions["anonymous"]; ok || anonymous.IsStored() {
+		t.Errorf("Expected anonymous session to be deleted.")
+	}
+
+	if err := session.MigrateAnonymous(session); err == nil {
+		t.Errorf("Expected error when migrating session into itself.")
+	}
+}
@@ -0,0 +1,45 @@
+package sessions
+
+import "encoding/json"
+
+// Serializer encodes and decodes the flashes and values of sessions, so stores
+// can save them. Stores may require the encoding to be text, e.g. stores that
+// save to text columns of a database.
+type Serializer interface {
+	// MarshalFlashes encodes flashes.
+	MarshalFlashes(flashes []Flash) ([]byte, error)
+
+	// MarshalValues encodes values.
+	MarshalValues(values map[string]string) ([]byte, error)
+
+	// UnmarshalFlashes decodes flashes encoded by MarshalFlashes. The result
+	// can be used as input for Flashes.Add.
+	UnmarshalFlashes(data []byte) ([]Flash, error)
+
+	// UnmarshalValues decodes values encoded by MarshalValues. The result can
+	// be used as input for Values.SetAll.
+	UnmarshalValues(data []byte) (map[string]string, error)
+}
+
+// JSONSerializer is a Serializer that uses JSON.
+type JSONSerializer struct{}
+
+// MarshalFlashes JSON encodes flashes.
+func (JSONSerializer) MarshalFlashes(flashes []Flash) ([]byte, error) {
+	return json.Marshal(flashes)
+}
+
+// MarshalValues JSON encodes values.
+func (JSONSerializer) MarshalValues(values map[string]string) ([]byte, error) {
+	return json.Marshal(values)
+}
+
+// UnmarshalFlashes decodes flashes with FlashesFromJSON.
+func (JSONSerializer) UnmarshalFlashes(data []byte) ([]Flash, error) {
+	return FlashesFromJSON(data)
+}
+
+// UnmarshalValues decodes values with ValuesFromJSON.
+func (JSONSerializer) UnmarshalValues(data []byte) (map[string]string, error) {
+	return ValuesFromJSON(data)
+}
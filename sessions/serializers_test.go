@@ -0,0 +1,30 @@
+package sessions
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJSONSerializer(t *testing.T) {
+	serializer := JSONSerializer{}
+
+	flashes := []Flash{NewFlash("a", "type a"), NewFlash("b", "")}
+	data, err := serializer.MarshalFlashes(flashes)
+	if err != nil {
+		t.Fatalf("MarshalFlashes failed: %s", err)
+	} else if result, err := serializer.UnmarshalFlashes(data); err != nil {
+		t.Fatalf("UnmarshalFlashes failed: %s", err)
+	} else if !reflect.DeepEqual(result, flashes) {
+		t.Errorf("Expected %v, got %v", flashes, result)
+	}
+
+	values := map[string]string{"a": "1", "b": ""}
+	data, err = serializer.MarshalValues(values)
+	if err != nil {
+		t.Fatalf("MarshalValues failed: %s", err)
+	} else if result, err := serializer.UnmarshalValues(data); err != nil {
+		t.Fatalf("UnmarshalValues failed: %s", err)
+	} else if !reflect.DeepEqual(result, values) {
+		t.Errorf("Expected %v, got %v", values, result)
+	}
+}
@@ -6,8 +6,22 @@ import (
 	"time"
 )
 
+// KeyUserID is the key under which the ID of the user who owns a session is
+// stored in the session’s values. Stores may put the user ID in an indexed
+// column, which makes it possible to delete all sessions of a particular user.
+var KeyUserID = "user.id"
+
+// KeyImpersonators is the key under which ImpersonateUser stores the IDs of
+// the users who impersonate the session’s current user.
+var KeyImpersonators = "user.impersonators"
+
 // Session represents an HTTP(S) session.
 type Session interface {
+	// CSRFToken returns the session’s token for protecting forms against
+	// cross-site request forgery. The token is generated on first use and
+	// stored in the session’s values, see VerifyCSRFToken.
+	CSRFToken() (string, error)
+
 	// DateCreated returns the session’s creation date.
 	DateCreated() time.Time
 
@@ -20,22 +34,72 @@ type Session interface {
 	// ID returns the session’s ID.
 	ID() string
 
+	// IPAddress returns the IP address of the client that created the
+	// session, or an empty string if it is unknown. See SetClient.
+	IPAddress() string
+
+	// ImpersonateUser makes userID the session’s user ID, e.g. for an
+	// administrator logging in as another user. The previous user ID is
+	// remembered, so it can be restored with StopImpersonating.
+	// Impersonations can be nested.
+	ImpersonateUser(userID string) error
+
+	// IsDirty returns true if the session was changed since it was created,
+	// loaded from or saved to the store. Changes are made by SetDateCreated,
+	// and by the methods of Values and Flashes that add, set or remove items.
+	// Changing a Flash itself does not mark the session as dirty.
+	IsDirty() bool
+
 	// IsStored returns true if the session exists in the store.
 	IsStored() bool
 
-	// Save saves the session to the session store.
+	// MigrateAnonymous copies the values and flashes of from, e.g. the session
+	// of an anonymous user who just logged in, into the session and deletes
+	// from. Values that belong to from’s identity, e.g. the user ID and CSRF
+	// token, are not copied. The session must be saved afterwards.
+	MigrateAnonymous(from Session) error
+
+	// Save saves the session to the session store. See Store.Save for when
+	// the session is actually written.
 	Save(http.ResponseWriter) error
 
+	// SetClient sets the IP address and user agent of the client that created
+	// the session, e.g. for showing users where they are logged in. They are
+	// stored in the session’s values. Stores may call SetClient when they
+	// create a session.
+	SetClient(ipAddress, userAgent string)
+
 	// SetDateCreated sets the session’s creation date.
 	SetDateCreated(time.Time)
 
+	// SetIsDirty sets whether the session was changed. Only the store should
+	// call this method.
+	SetIsDirty(bool)
+
 	// SetIsStored sets whether the session exists in the store. Only the store
 	// should call this method.
 	SetIsStored(bool)
 
+	// SetUserID sets the ID of the user who owns the session. An empty ID
+	// removes the user ID.
+	SetUserID(string)
+
+	// StopImpersonating restores the user ID that was replaced by the last
+	// call of ImpersonateUser. If the session’s user is not impersonated,
+	// nothing happens.
+	StopImpersonating() error
+
 	// Store returns the session store.
 	Store() Store
 
+	// UserAgent returns the user agent of the client that created the
+	// session, or an empty string if it is unknown. See SetClient.
+	UserAgent() string
+
+	// UserID returns the ID of the user who owns the session, or an empty
+	// string if the session has no user ID.
+	UserID() string
+
 	// Values returns the session’s value container.
 	Values() Values
 }
@@ -45,6 +109,7 @@ type session struct {
 	dateCreated time.Time
 	flashes     Flashes
 	id          string
+	isDirty     bool
 	isStored    bool
 	store       Store
 	values      Values
@@ -53,13 +118,14 @@ type session struct {
 // NewSession returns a new session. The session has not been saved to the
 // session store yet. To do that, call Save.
 func NewSession(store Store, id string) Session {
-	return &session{
+	s := &session{
 		dateCreated: time.Now(),
-		flashes:     NewFlashes(),
 		id:          id,
 		store:       store,
-		values:      NewValues(),
 	}
+	s.flashes = &trackedFlashes{Flashes: NewFlashes(), session: s}
+	s.values = &trackedValues{Values: NewValues(), session: s}
+	return s
 }
 
 // DateCreated returns the session’s creation date.
@@ -86,6 +152,29 @@ func (s *session) ID() string {
 	return s.id
 }
 
+// ImpersonateUser makes userID the session’s user ID and remembers the
+// previous user ID.
+func (s *session) ImpersonateUser(userID string) error {
+	var impersonators []string
+	if err := s.values.GetJSON(KeyImpersonators, &impersonators); err != nil {
+		return err
+	}
+
+	impersonators = append(impersonators, s.UserID())
+	if err := s.values.SetJSON(KeyImpersonators, impersonators); err != nil {
+		return err
+	}
+
+	s.SetUserID(userID)
+	return nil
+}
+
+// IsDirty returns true if the session was changed since it was created, loaded
+// from or saved to the store.
+func (s *session) IsDirty() bool {
+	return s.isDirty
+}
+
 // IsStored returns true if the session exists in the store.
 func (s *session) IsStored() bool {
 	return s.isStored
@@ -99,6 +188,12 @@ func (s *session) Save(writer http.ResponseWriter) error {
 // SetDateCreated sets the session’s creation date.
 func (s *session) SetDateCreated(date time.Time) {
 	s.dateCreated = date
+	s.isDirty = true
+}
+
+// SetIsDirty sets whether the session was changed.
+func (s *session) SetIsDirty(isDirty bool) {
+	s.isDirty = isDirty
 }
 
 // SetIsStored sets whether the session exists in the store.
@@ -106,11 +201,45 @@ func (s *session) SetIsStored(isStored bool) {
 	s.isStored = isStored
 }
 
+// SetUserID sets the ID of the user who owns the session.
+func (s *session) SetUserID(userID string) {
+	if userID == "" {
+		s.values.Remove(KeyUserID)
+		return
+	}
+	s.values.Set(KeyUserID, userID)
+}
+
+// StopImpersonating restores the user ID that was replaced by the last call of
+// ImpersonateUser.
+func (s *session) StopImpersonating() error {
+	var impersonators []string
+	if err := s.values.GetJSON(KeyImpersonators, &impersonators); err != nil {
+		return err
+	} else if len(impersonators) == 0 {
+		return nil
+	}
+
+	last := len(impersonators) - 1
+	s.SetUserID(impersonators[last])
+
+	if last == 0 {
+		s.values.Remove(KeyImpersonators)
+		return nil
+	}
+	return s.values.SetJSON(KeyImpersonators, impersonators[:last])
+}
+
 // Store returns the session store.
 func (s session) Store() Store {
 	return s.store
 }
 
+// UserID returns the ID of the user who owns the session.
+func (s *session) UserID() string {
+	return s.values.Get(KeyUserID)
+}
+
 // Values returns the session’s value container.
 func (s session) Values() Values {
 	return s.values
@@ -32,3 +32,105 @@ func TestSession_SetDateCreated(t *testing.T) {
 		t.Errorf("Expected DateCreated %s, got %s", date, session.DateCreated())
 	}
 }
+
+func TestSession_UserID(t *testing.T) {
+	session := NewSession(nil, "session123")
+	if userID := session.UserID(); userID != "" {
+		t.Errorf("Expected empty user ID, got %q.", userID)
+	}
+
+	session.SetUserID("user1")
+	if userID := session.UserID(); userID != "user1" {
+		t.Errorf("Expected user ID %q, got %q.", "user1", userID)
+	} else if value := session.Values().Get(KeyUserID); value != "user1" {
+		t.Errorf("Expected value %q for key %q, got %q.", "user1", KeyUserID, value)
+	}
+
+	session.SetUserID("")
+	if _, ok := session.Values().GetAll()[KeyUserID]; ok {
+		t.Errorf("Expected user ID to be removed.")
+	}
+}
+
+func TestSession_ImpersonateUser(t *testing.T) {
+	session := NewSession(nil, "session123")
+	session.SetUserID("admin")
+
+	if err := session.ImpersonateUser("user1"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if err := session.ImpersonateUser("user2"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if userID := session.UserID(); userID != "user2" {
+		t.Errorf("Expected user ID %q, got %q.", "user2", userID)
+	}
+
+	for _, expected := range []string{"user1", "admin", "admin"} {
+		if err := session.StopImpersonating(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		} else if userID := session.UserID(); userID != expected {
+			t.Errorf("Expected user ID %q, got %q.", expected, userID)
+		}
+	}
+
+	if _, ok := session.Values().GetAll()[KeyImpersonators]; ok {
+		t.Errorf("Expected impersonators to be removed.")
+	}
+}
+
+func TestSession_IsDirty(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(Session)
+	}{
+		{"Flashes().Add", func(s Session) { s.Flashes().Add(NewFlash("a", "")) }},
+		{"Flashes().AddNew", func(s Session) { s.Flashes().AddNew("a") }},
+		{"Flashes().Remove", func(s Session) { s.Flashes().Remove() }},
+		{"Flashes().RemoveAll", func(s Session) { s.Flashes().RemoveAll() }},
+		{"SetDateCreated", func(s Session) { s.SetDateCreated(time.Unix(30, 0)) }},
+		{"SetUserID", func(s Session) { s.SetUserID("user1") }},
+		{"Values().Remove", func(s Session) { s.Values().Remove("a") }},
+		{"Values().RemoveAll", func(s Session) { s.Values().RemoveAll() }},
+		{"Values().Set", func(s Session) { s.Values().Set("a", "b") }},
+		{"Values().SetAll", func(s Session) { s.Values().SetAll(map[string]string{"a": "b"}) }},
+		{"Values().SetJSON", func(s Session) { s.Values().SetJSON("a", 1) }},
+	}
+
+	for _, test := range tests {
+		session := NewSession(nil, "session123")
+		if session.IsDirty() {
+			t.Fatalf("Expected new session to be clean.")
+		}
+
+		session.Flashes().GetAll()
+		session.Values().Get("a")
+		if session.IsDirty() {
+			t.Errorf("%s: Expected session to be clean after reading.", test.name)
+		}
+
+		test.change(session)
+		if !session.IsDirty() {
+			t.Errorf("%s: Expected session to be dirty.", test.name)
+		}
+
+		session.SetIsDirty(false)
+		if session.IsDirty() {
+			t.Errorf("%s: Expected session to be clean.", test.name)
+		}
+	}
+}
+
+func TestSession_SetClient(t *testing.T) {
+	session := NewSession(nil, "session123")
+	session.SetClient("192.0.2.1", "Test/1.0")
+
+	if ip := session.IPAddress(); ip != "192.0.2.1" {
+		t.Errorf("Expected IP address %q, got %q.", "192.0.2.1", ip)
+	} else if userAgent := session.UserAgent(); userAgent != "Test/1.0" {
+		t.Errorf("Expected user agent %q, got %q.", "Test/1.0", userAgent)
+	}
+
+	session.SetClient("", "")
+	if values := session.Values().GetAll(); len(values) != 0 {
+		t.Errorf("Expected client values to be removed, got %v.", values)
+	}
+}
@@ -0,0 +1,263 @@
+// Package sessionstest provides an in-memory session store for tests. It lets
+// tests of packages that use sessions run without a database, record which
+// store methods were called and make them fail on purpose.
+package sessionstest
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ChristianSiegert/go-packages/sessions"
+)
+
+// DefaultCookieName is the cookie name of stores returned by New.
+var DefaultCookieName = "sessionID"
+
+// Store is an in-memory implementation of sessions.Store. Sessions are
+// identified by a cookie, like with a cookie-based store. A Store is safe for
+// concurrent use.
+type Store struct {
+	// CookieName is the name of the cookie that contains the session ID.
+	CookieName string
+
+	// Errors contains errors keyed by method name, e.g. “Save”. If a method’s
+	// name is in Errors, the method returns the error without doing anything
+	// else. This is useful for testing error paths.
+	Errors map[string]error
+
+	calls    []string
+	mutex    sync.Mutex
+	nextID   int
+	sessions map[string]*storedSession
+}
+
+// storedSession contains the data of a saved session.
+type storedSession struct {
+	dateCreated time.Time
+	flashes     []sessions.Flash
+	values      map[string]string
+}
+
+// New returns a new, empty instance of Store.
+func New() *Store {
+	return &Store{
+		CookieName: DefaultCookieName,
+		Errors:     make(map[string]error),
+		sessions:   make(map[string]*storedSession),
+	}
+}
+
+// Calls returns the names of the called methods in order of calls, e.g.
+// []string{"Get", "Save"}.
+func (s *Store) Calls() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+// Delete deletes a session from the store, and deletes the session cookie.
+func (s *Store) Delete(writer http.ResponseWriter, sessionID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.call("Delete"); err != nil {
+		return err
+	}
+
+	delete(s.sessions, sessionID)
+	if writer != nil {
+		http.SetCookie(writer, &http.Cookie{MaxAge: -1, Name: s.CookieName, Path: "/"})
+	}
+	return nil
+}
+
+// DeleteExpired deletes sessions that were created before before, and returns
+// the number of deleted sessions.
+func (s *Store) DeleteExpired(before time.Time) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.call("DeleteExpired"); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for id, stored := range s.sessions {
+		if stored.dateCreated.Before(before) {
+			delete(s.sessions, id)
+			count++
+		}
+	}
+	return count, nil
+}
+
+// DeleteMulti deletes sessions that match filter.
+func (s *Store) DeleteMulti(filter *sessions.Filter) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.call("DeleteMulti"); err != nil {
+		return err
+	}
+
+	for id, stored := range s.sessions {
+		if matches(filter, id, stored) {
+			delete(s.sessions, id)
+		}
+	}
+	return nil
+}
+
+// Get gets the session whose ID is in the session cookie. If there is no
+// cookie or no session with the ID, a new session is returned. New sessions
+// get sequential IDs, e.g. “session-1”.
+func (s *Store) Get(writer http.ResponseWriter, request *http.Request) (sessions.Session, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.call("Get"); err != nil {
+		return nil, err
+	}
+
+	if cookie, err := request.Cookie(s.CookieName); err == nil {
+		if stored, ok := s.sessions[cookie.Value]; ok {
+			return s.restore(cookie.Value, stored), nil
+		}
+	}
+
+	s.nextID++
+	return sessions.NewSession(s, "session-"+strconv.Itoa(s.nextID)), nil
+}
+
+// GetMulti gets sessions that match filter.
+func (s *Store) GetMulti(filter *sessions.Filter) ([]sessions.Session, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.call("GetMulti"); err != nil {
+		return nil, err
+	}
+
+	var result []sessions.Session
+	for id, stored := range s.sessions {
+		if matches(filter, id, stored) {
+			result = append(result, s.restore(id, stored))
+		}
+	}
+	return result, nil
+}
+
+// Len returns the number of sessions in the store.
+func (s *Store) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.sessions)
+}
+
+// Save saves a session to the store and sets the session cookie. If the
+// session is stored and not dirty, Save does nothing.
+func (s *Store) Save(writer http.ResponseWriter, session sessions.Session) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.call("Save"); err != nil {
+		return err
+	}
+
+	if session.IsStored() && !session.IsDirty() {
+		return nil
+	}
+
+	s.save(session)
+	if writer != nil {
+		http.SetCookie(writer, &http.Cookie{HttpOnly: true, Name: s.CookieName, Path: "/", Value: session.ID()})
+	}
+	return nil
+}
+
+// SaveMulti saves the provided sessions and marks them as stored and clean.
+func (s *Store) SaveMulti(batch []sessions.Session) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.call("SaveMulti"); err != nil {
+		return err
+	}
+
+	for _, session := range batch {
+		s.save(session)
+	}
+	return nil
+}
+
+// call records a call of method and returns the error configured for it.
+func (s *Store) call(method string) error {
+	s.calls = append(s.calls, method)
+	return s.Errors[method]
+}
+
+// restore returns a new session that has the data of stored.
+func (s *Store) restore(id string, stored *storedSession) sessions.Session {
+	session := sessions.NewSession(s, id)
+	session.SetDateCreated(stored.dateCreated)
+
+	for _, flash := range stored.flashes {
+		session.Flashes().Add(sessions.NewFlash(flash.Message(), flash.Type()))
+	}
+	session.Values().SetAll(stored.values)
+	session.SetIsStored(true)
+	session.SetIsDirty(false)
+	return session
+}
+
+// save stores a copy of session’s data and marks session as stored and clean.
+func (s *Store) save(session sessions.Session) {
+	stored := &storedSession{
+		dateCreated: session.DateCreated(),
+		values:      make(map[string]string),
+	}
+
+	for _, flash := range session.Flashes().GetAll() {
+		stored.flashes = append(stored.flashes, sessions.NewFlash(flash.Message(), flash.Type()))
+	}
+	for key, value := range session.Values().GetAll() {
+		stored.values[key] = value
+	}
+
+	s.sessions[session.ID()] = stored
+	session.SetIsStored(true)
+	session.SetIsDirty(false)
+}
+
+// matches returns whether the session with the provided ID and data matches
+// filter, see sessions.Filter.
+func matches(filter *sessions.Filter, id string, stored *storedSession) bool {
+	if filter == nil {
+		return true
+	}
+
+	if len(filter.IDs) > 0 || len(filter.UserIDs) > 0 {
+		if !contains(filter.IDs, id) && !contains(filter.UserIDs, stored.values[sessions.KeyUserID]) {
+			return false
+		}
+	}
+
+	before, after := filter.DateCreatedBefore, filter.DateCreatedAfter
+	if before.IsZero() && after.IsZero() {
+		return true
+	}
+	return !before.IsZero() && stored.dateCreated.Before(before) ||
+		!after.IsZero() && stored.dateCreated.After(after)
+}
+
+// contains returns whether values contains value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
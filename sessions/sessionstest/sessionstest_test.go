@@ -0,0 +1,101 @@
+package sessionstest
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ChristianSiegert/go-packages/sessions"
+)
+
+func TestStore_Get_Save(t *testing.T) {
+	store := New()
+
+	recorder := httptest.NewRecorder()
+	session, err := store.Get(recorder, httptest.NewRequest("GET", "/", nil))
+	if err != nil {
+		t.Fatalf("Get failed: %s", err)
+	} else if session.IsStored() {
+		t.Errorf("Expected new session not to be stored.")
+	}
+
+	session.Values().Set("foo", "bar")
+	if err := session.Save(recorder); err != nil {
+		t.Fatalf("Save failed: %s", err)
+	}
+
+	request := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range recorder.Result().Cookies() {
+		request.AddCookie(cookie)
+	}
+
+	result, err := store.Get(httptest.NewRecorder(), request)
+	if err != nil {
+		t.Fatalf("Get failed: %s", err)
+	} else if result.ID() != session.ID() {
+		t.Errorf("Expected session %q, got %q", session.ID(), result.ID())
+	} else if value := result.Values().Get("foo"); value != "bar" {
+		t.Errorf("Expected value %q, got %q", "bar", value)
+	} else if !result.IsStored() || result.IsDirty() {
+		t.Errorf("Expected session to be stored and clean.")
+	}
+
+	if expected := []string{"Get", "Save", "Get"}; !reflect.DeepEqual(store.Calls(), expected) {
+		t.Errorf("Expected calls %v, got %v", expected, store.Calls())
+	}
+}
+
+func TestStore_Errors(t *testing.T) {
+	store := New()
+	expected := errors.New("foo")
+	store.Errors["Save"] = expected
+
+	session := sessions.NewSession(store, "a")
+	if err := session.Save(httptest.NewRecorder()); err != expected {
+		t.Errorf("Expected error %v, got %v", expected, err)
+	} else if store.Len() != 0 {
+		t.Errorf("Expected no stored sessions, got %d", store.Len())
+	}
+}
+
+func TestStore_GetMulti_DeleteMulti_DeleteExpired(t *testing.T) {
+	store := New()
+	now := time.Date(2099, 12, 31, 13, 14, 15, 0, time.UTC)
+
+	for i, id := range []string{"a", "b", "c"} {
+		session := sessions.NewSession(store, id)
+		session.SetDateCreated(now.Add(time.Duration(-i) * time.Hour))
+		session.SetUserID("user-" + id)
+		if err := store.SaveMulti([]sessions.Session{session}); err != nil {
+			t.Fatalf("SaveMulti failed: %s", err)
+		} else if !session.IsStored() || session.IsDirty() {
+			t.Errorf("Expected session %q to be stored and clean after SaveMulti.", id)
+		}
+	}
+
+	result, err := store.GetMulti(&sessions.Filter{IDs: []string{"a"}, UserIDs: []string{"user-b"}})
+	if err != nil {
+		t.Fatalf("GetMulti failed: %s", err)
+	} else if len(result) != 2 {
+		t.Errorf("Expected 2 sessions, got %d", len(result))
+	}
+
+	if err := store.DeleteMulti(&sessions.Filter{UserIDs: []string{"user-a"}}); err != nil {
+		t.Fatalf("DeleteMulti failed: %s", err)
+	}
+
+	count, err := store.DeleteExpired(now.Add(-90 * time.Minute))
+	if err != nil {
+		t.Fatalf("DeleteExpired failed: %s", err)
+	} else if count != 1 {
+		t.Errorf("Expected 1 deleted session, got %d", count)
+	}
+
+	if result, err := store.GetMulti(nil); err != nil {
+		t.Fatalf("GetMulti failed: %s", err)
+	} else if len(result) != 1 || result[0].ID() != "b" {
+		t.Errorf("Expected only session %q to remain, got %d sessions", "b", len(result))
+	}
+}
@@ -0,0 +1,45 @@
+package sqlsessionstores
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ChristianSiegert/go-packages/sessions"
+)
+
+func TestStore_saveBatchQuery(t *testing.T) {
+	store := &Store{Dialect: DialectPostgreSQL, TableName: "test_sessions"}
+	query := store.saveBatchQuery(2)
+
+	expected := "VALUES ($1, $2, $3, $4, $5), ($6, $7, $8, $9, $10)"
+	if !strings.Contains(query, expected) {
+		t.Errorf("Expected query to contain %q, got %q.", expected, query)
+	}
+	if !strings.Contains(query, "INSERT INTO test_sessions") {
+		t.Errorf("Expected query to insert into test_sessions, got %q.", query)
+	}
+}
+
+func TestWithUniqueIDs(t *testing.T) {
+	store := &Store{}
+	a1 := sessions.NewSession(store, "a")
+	b := sessions.NewSession(store, "b")
+	a2 := sessions.NewSession(store, "a")
+	c := sessions.NewSession(store, "c")
+
+	tests := []struct {
+		ss       []sessions.Session
+		expected []sessions.Session
+	}{
+		{nil, nil},
+		{[]sessions.Session{a1, b, c}, []sessions.Session{a1, b, c}},
+		{[]sessions.Session{a1, b, a2, c}, []sessions.Session{b, a2, c}},
+	}
+
+	for i, test := range tests {
+		if result := withUniqueIDs(test.ss); !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("%d. Expected %v, got %v.", i, test.expected, result)
+		}
+	}
+}
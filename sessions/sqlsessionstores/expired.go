@@ -0,0 +1,66 @@
+package sqlsessionstores
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ChristianSiegert/go-packages/sessions"
+)
+
+// DeleteExpired deletes sessions that were created before before, and returns
+// the number of deleted sessions. If before is zero, no sessions are deleted.
+func (s *Store) DeleteExpired(before time.Time) (int, error) {
+	if before.IsZero() {
+		return 0, nil
+	}
+
+	where, args := s.where(&sessions.Filter{DateCreatedBefore: before})
+	query := fmt.Sprintf(queries[s.Dialect][queryDeleteMulti], s.TableName) + where
+
+	result, err := s.DB.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	return int(count), err
+}
+
+// DeleteExpiredEvery deletes sessions that were created more than s.Expiration
+// ago every interval until ctx is done.
+// Errors are passed to onError. If onError is nil, errors are logged with the
+// standard logger. If interval is not positive, DeleteExpiredEvery reports an
+// error and returns immediately. Otherwise it blocks, so it is usually started
+// in its own goroutine, e.g.
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	defer cancel()
+//	go store.DeleteExpiredEvery(ctx, time.Hour, nil)
+func (s *Store) DeleteExpiredEvery(ctx context.Context, interval time.Duration, onError func(error)) {
+	if onError == nil {
+		onError = func(err error) {
+			log.Printf("sqlsessionstores: deleting expired sessions failed: %s", err)
+		}
+	}
+
+	if interval <= 0 {
+		onError(fmt.Errorf("sqlsessionstores: DeleteExpiredEvery interval must be positive, got %s", interval))
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DeleteExpired(s.now().Add(-s.Expiration)); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package sqlsessionstores
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ChristianSiegert/go-packages/sessions"
+)
+
+// setNow makes store use date as current time.
+func setNow(store *Store, date time.Time) {
+	store.clock = func() time.Time {
+		return date
+	}
+}
+
+func TestStore_saveCookie(t *testing.T) {
+	now := time.Date(2099, 12, 31, 13, 14, 15, 0, time.UTC)
+
+	store := &Store{
+		AuthOptions: AuthOptions{CookieName: "session", CookiePath: "/", CookieSameSite: http.SameSiteStrictMode},
+		Expiration:  time.Hour,
+	}
+	setNow(store, now)
+
+	tests := []struct {
+		dateCreated    time.Time
+		expectedMaxAge int
+	}{
+		{now, 3600},
+		{now.Add(-30 * time.Minute), 1800},
+	}
+
+	for _, test := range tests {
+		session := sessions.NewSession(store, "session123")
+		session.SetDateCreated(test.dateCreated)
+
+		recorder := httptest.NewRecorder()
+		store.saveCookie(recorder, session)
+
+		cookies := recorder.Result().Cookies()
+		if len(cookies) != 1 {
+			t.Fatalf("Expected 1 cookie, got %d", len(cookies))
+		} else if expected := test.dateCreated.Add(time.Hour); !cookies[0].Expires.Equal(expected) {
+			t.Errorf("Expected Expires %s, got %s", expected, cookies[0].Expires)
+		} else if cookies[0].MaxAge != test.expectedMaxAge {
+			t.Errorf("Expected MaxAge %d, got %d", test.expectedMaxAge, cookies[0].MaxAge)
+		} else if cookies[0].SameSite != http.SameSiteStrictMode {
+			t.Errorf("Expected SameSite %d, got %d", http.SameSiteStrictMode, cookies[0].SameSite)
+		}
+	}
+}
+
+func TestStore_newSession(t *testing.T) {
+	now := time.Date(2099, 12, 31, 13, 14, 15, 0, time.UTC)
+
+	store := &Store{Strength: 8}
+	setNow(store, now)
+
+	session, err := store.newSession()
+	if err != nil {
+		t.Fatalf("Creating session failed unexpectedly: %s", err)
+	} else if !session.DateCreated().Equal(now) {
+		t.Errorf("Expected DateCreated %s, got %s", now, session.DateCreated())
+	}
+}
+
+func TestStore_zeroClock(t *testing.T) {
+	store := &Store{AuthOptions: AuthOptions{CookieName: "session"}, Expiration: time.Hour, Strength: 8}
+
+	session, err := store.newSession()
+	if err != nil {
+		t.Fatalf("Creating session failed unexpectedly: %s", err)
+	} else if time.Since(session.DateCreated()) > time.Minute {
+		t.Errorf("Expected DateCreated to be now, got %s", session.DateCreated())
+	}
+
+	recorder := httptest.NewRecorder()
+	store.saveCookie(recorder, session)
+	store.deleteCookie(recorder)
+	if cookies := recorder.Result().Cookies(); len(cookies) != 2 {
+		t.Errorf("Expected 2 cookies, got %d", len(cookies))
+	}
+}
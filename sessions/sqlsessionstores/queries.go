@@ -1,10 +1,16 @@
 package sqlsessionstores
 
 const (
-	queryCreate = "create"
-	queryDelete = "delete"
-	queryGet    = "get"
-	querySave   = "save"
+	queryCreate      = "create"
+	queryDelete      = "delete"
+	queryDeleteMulti = "deleteMulti"
+	queryGet         = "get"
+	queryGetMulti    = "getMulti"
+	queryLimitUser   = "limitUser"
+	queryReserialize = "reserialize"
+	querySave        = "save"
+	querySaveBatch   = "saveBatch"
+	queryScanData    = "scanData"
 )
 
 var queries = map[string]map[string]string{
@@ -28,7 +34,8 @@ var queries = map[string]map[string]string{
 				date_created
 			);
 		`,
-		queryDelete: "DELETE FROM %s WHERE id = $1",
+		queryDelete:      "DELETE FROM %s WHERE id = $1",
+		queryDeleteMulti: "DELETE FROM %s",
 		queryGet: `
 			SELECT
 				data,
@@ -41,6 +48,26 @@ var queries = map[string]map[string]string{
 				id = $1
 			LIMIT 1
 		`,
+		queryGetMulti: `
+			SELECT
+				data,
+				date_created,
+				flashes,
+				id
+			FROM
+				%s
+		`,
+		queryLimitUser: `
+			DELETE FROM %s
+			WHERE id IN (
+				SELECT id
+				FROM %s
+				WHERE user_id = $1 AND id != $2
+				ORDER BY date_created DESC, id DESC
+				OFFSET $3
+			)
+		`,
+		queryReserialize: "UPDATE %s SET data = $1, flashes = $2 WHERE id = $3",
 		querySave: `
 			INSERT INTO %s (
 				data, date_created, flashes, id, user_id
@@ -52,6 +79,28 @@ var queries = map[string]map[string]string{
 				flashes = $3,
 				user_id = $5
 		`,
+		querySaveBatch: `
+			INSERT INTO %s (
+				data, date_created, flashes, id, user_id
+			) VALUES %s
+			ON CONFLICT (id) DO UPDATE SET
+				data = EXCLUDED.data,
+				date_created = EXCLUDED.date_created,
+				flashes = EXCLUDED.flashes,
+				user_id = EXCLUDED.user_id
+		`,
+		queryScanData: `
+			SELECT
+				data,
+				flashes,
+				id
+			FROM
+				%s
+			WHERE
+				id > $1
+			ORDER BY id
+			LIMIT $2
+		`,
 	},
 
 	DialectSQLite: map[string]string{
@@ -73,7 +122,8 @@ var queries = map[string]map[string]string{
 				date_created
 			);
 		`,
-		queryDelete: "DELETE FROM %s WHERE id = ?",
+		queryDelete:      "DELETE FROM %s WHERE id = ?",
+		queryDeleteMulti: "DELETE FROM %s",
 		queryGet: `
 			SELECT
 				data,
@@ -86,6 +136,26 @@ var queries = map[string]map[string]string{
 				id = ?
 			LIMIT 1
 		`,
+		queryGetMulti: `
+			SELECT
+				data,
+				date_created,
+				flashes,
+				id
+			FROM
+				%s
+		`,
+		queryLimitUser: `
+			DELETE FROM %s
+			WHERE id IN (
+				SELECT id
+				FROM %s
+				WHERE user_id = ? AND id != ?
+				ORDER BY date_created DESC, id DESC
+				LIMIT -1 OFFSET ?
+			)
+		`,
+		queryReserialize: "UPDATE %s SET data = ?, flashes = ? WHERE id = ?",
 		querySave: `
 			INSERT OR REPLACE INTO %s (
 				data, date_created, flashes, id, user_id
@@ -93,5 +163,17 @@ var queries = map[string]map[string]string{
 				?, ?, ?, ?, ?
 			);
 		`,
+		queryScanData: `
+			SELECT
+				data,
+				flashes,
+				id
+			FROM
+				%s
+			WHERE
+				id > ?
+			ORDER BY id
+			LIMIT ?
+		`,
 	},
 }
@@ -0,0 +1,122 @@
+package sqlsessionstores
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/ChristianSiegert/go-packages/sessions"
+)
+
+// Reserialize re-encodes the flashes and values of all stored sessions that
+// were encoded by old, so they are encoded by s.Serializer. It returns the
+// number of updated sessions. This is needed when the serializer changes, e.g.
+// when an encrypting Serializer rotates its key: pass a Serializer with the
+// old key as old and set s.Serializer to one with the new key.
+//
+// Sessions are updated in batches of at most maxBatchSize sessions, each
+// within its own transaction. Sessions that s.Serializer can already decode
+// are skipped, so if Reserialize fails, it can be run again to continue.
+func (s *Store) Reserialize(old sessions.Serializer) (int, error) {
+	count, lastID := 0, ""
+	for {
+		n, id, err := s.reserializeBatch(old, lastID)
+		count += n
+		if err != nil || id == "" {
+			return count, err
+		}
+		lastID = id
+	}
+}
+
+// reserializeBatch re-encodes up to maxBatchSize sessions whose ID is greater
+// than afterID, see Reserialize. It returns the number of updated sessions and
+// the greatest ID that was processed, or an empty string if no sessions were
+// left.
+func (s *Store) reserializeBatch(old sessions.Serializer, afterID string) (count int, lastID string, e error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, "", err
+	}
+
+	// If tx was not committed, rollback. If rollback fails, return rollback’s
+	// error instead of the original error.
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			e = err
+		}
+	}()
+
+	type row struct {
+		encodedFlashes []byte
+		encodedValues  []byte
+		id             string
+	}
+
+	rows, err := tx.Query(fmt.Sprintf(queries[s.Dialect][queryScanData], s.TableName), afterID, maxBatchSize)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var batch []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.encodedValues, &r.encodedFlashes, &r.id); err != nil {
+			rows.Close()
+			return 0, "", err
+		}
+		batch = append(batch, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, "", err
+	}
+
+	query := fmt.Sprintf(queries[s.Dialect][queryReserialize], s.TableName)
+	for _, r := range batch {
+		lastID = r.id
+
+		if s.canDecode(r.encodedFlashes, r.encodedValues) {
+			continue
+		}
+
+		flashes, err := old.UnmarshalFlashes(r.encodedFlashes)
+		if err != nil {
+			return 0, "", fmt.Errorf("sqlsessionstores: decoding flashes of session %q failed: %s", r.id, err)
+		}
+
+		values, err := old.UnmarshalValues(r.encodedValues)
+		if err != nil {
+			return 0, "", fmt.Errorf("sqlsessionstores: decoding values of session %q failed: %s", r.id, err)
+		}
+
+		encodedFlashes, err := s.serializer().MarshalFlashes(flashes)
+		if err != nil {
+			return 0, "", err
+		}
+
+		encodedValues, err := s.serializer().MarshalValues(values)
+		if err != nil {
+			return 0, "", err
+		}
+
+		if _, err := tx.Exec(query, encodedValues, encodedFlashes, r.id); err != nil {
+			return 0, "", err
+		}
+		count++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, "", err
+	}
+	return count, lastID, nil
+}
+
+// canDecode returns whether s.Serializer can decode the encoded flashes and
+// values.
+func (s *Store) canDecode(encodedFlashes, encodedValues []byte) bool {
+	if _, err := s.serializer().UnmarshalFlashes(encodedFlashes); err != nil {
+		return false
+	}
+	_, err := s.serializer().UnmarshalValues(encodedValues)
+	return err == nil
+}
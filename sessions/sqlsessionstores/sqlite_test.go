@@ -0,0 +1,525 @@
+package sqlsessionstores
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ChristianSiegert/go-packages/sessions"
+
+	// Register SQL driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// newSQLiteStore returns a store backed by a temporary SQLite database. The
+// database is closed when the test finishes.
+func newSQLiteStore(t *testing.T) *Store {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatalf("Opening database failed: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	store, err := New(DialectSQLite, db, "test_sessions")
+	if err != nil {
+		t.Fatalf("Creating store failed: %s", err)
+	}
+	return store
+}
+
+func TestStore_where(t *testing.T) {
+	date1 := time.Date(2090, 1, 2, 3, 4, 5, 0, time.UTC)
+	date2 := time.Date(2091, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		dialect       string
+		filter        *sessions.Filter
+		expectedWhere string
+		expectedArgs  []interface{}
+	}{
+		{DialectPostgreSQL, nil, "", nil},
+		{DialectPostgreSQL, &sessions.Filter{}, "", nil},
+		{
+			DialectPostgreSQL,
+			&sessions.Filter{IDs: []string{"a", "b"}, UserIDs: []string{"c"}},
+			" WHERE (id IN ($1, $2) OR user_id IN ($3))",
+			[]interface{}{"a", "b", "c"},
+		},
+		{
+			DialectSQLite,
+			&sessions.Filter{DateCreatedAfter: date2, DateCreatedBefore: date1, UserIDs: []string{"c"}},
+			" WHERE (user_id IN (?)) AND (date_created < ? OR date_created > ?)",
+			[]interface{}{"c", date1, date2},
+		},
+	}
+
+	for i, test := range tests {
+		store := &Store{Dialect: test.dialect}
+
+		if where, args := store.where(test.filter); where != test.expectedWhere {
+			t.Errorf("Test %d: Expected %q, got %q", i+1, test.expectedWhere, where)
+		} else if !reflect.DeepEqual(args, test.expectedArgs) {
+			t.Errorf("Test %d: Expected %#v, got %#v", i+1, test.expectedArgs, args)
+		}
+	}
+}
+
+func TestStore_GetMulti_DeleteMulti(t *testing.T) {
+	store := newSQLiteStore(t)
+
+	sessionA := sessions.NewSession(store, "a")
+	sessionA.SetDateCreated(time.Date(2090, 1, 1, 0, 0, 0, 0, time.UTC))
+	sessionA.Flashes().AddNew("lorem", "ipsum")
+	sessionA.SetUserID("user-a")
+
+	sessionB := sessions.NewSession(store, "b")
+	sessionB.SetDateCreated(time.Date(2091, 1, 1, 0, 0, 0, 0, time.UTC))
+	sessionB.SetUserID("user-b")
+
+	sessionC := sessions.NewSession(store, "c")
+	sessionC.SetDateCreated(time.Date(2092, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if err := store.SaveMulti([]sessions.Session{sessionA, sessionB, sessionC}); err != nil {
+		t.Fatalf("SaveMulti failed: %s", err)
+	}
+	for _, session := range []sessions.Session{sessionA, sessionB, sessionC} {
+		if !session.IsStored() || session.IsDirty() {
+			t.Errorf("Expected session %q to be stored and clean after SaveMulti.", session.ID())
+		}
+	}
+
+	tests := []struct {
+		filter      *sessions.Filter
+		expectedIDs []string
+	}{
+		{nil, []string{"a", "b", "c"}},
+		{&sessions.Filter{IDs: []string{"c"}, UserIDs: []string{"user-a"}}, []string{"a", "c"}},
+		{&sessions.Filter{DateCreatedAfter: sessionA.DateCreated()}, []string{"b", "c"}},
+		{&sessions.Filter{DateCreatedBefore: sessionB.DateCreated()}, []string{"a"}},
+	}
+
+	for i, test := range tests {
+		ss, err := store.GetMulti(test.filter)
+		if err != nil {
+			t.Fatalf("Test %d: GetMulti failed: %s", i+1, err)
+		}
+
+		ids := make([]string, 0, len(ss))
+		for _, session := range ss {
+			ids = append(ids, session.ID())
+		}
+
+		if !reflect.DeepEqual(ids, test.expectedIDs) {
+			t.Errorf("Test %d: Expected IDs %v, got %v", i+1, test.expectedIDs, ids)
+		}
+	}
+
+	if ss, err := store.GetMulti(&sessions.Filter{IDs: []string{"a"}}); err != nil {
+		t.Fatalf("GetMulti failed: %s", err)
+	} else if len(ss) != 1 {
+		t.Fatalf("Expected 1 session, got %d", len(ss))
+	} else if !reflect.DeepEqual(ss[0].Flashes().GetAll(), sessionA.Flashes().GetAll()) {
+		t.Errorf("Expected flashes %v, got %v", sessionA.Flashes().GetAll(), ss[0].Flashes().GetAll())
+	} else if !reflect.DeepEqual(ss[0].Values().GetAll(), sessionA.Values().GetAll()) {
+		t.Errorf("Expected values %v, got %v", sessionA.Values().GetAll(), ss[0].Values().GetAll())
+	} else if !ss[0].DateCreated().Equal(sessionA.DateCreated()) {
+		t.Errorf("Expected DateCreated %s, got %s", sessionA.DateCreated(), ss[0].DateCreated())
+	}
+
+	if err := store.DeleteMulti(&sessions.Filter{UserIDs: []string{"user-b"}}); err != nil {
+		t.Fatalf("DeleteMulti failed: %s", err)
+	}
+	if ss, err := store.GetMulti(nil); err != nil {
+		t.Fatalf("GetMulti failed: %s", err)
+	} else if len(ss) != 2 {
+		t.Errorf("Expected 2 sessions, got %d", len(ss))
+	}
+
+	if err := store.DeleteMulti(nil); err != nil {
+		t.Fatalf("DeleteMulti failed: %s", err)
+	}
+	if ss, err := store.GetMulti(nil); err != nil {
+		t.Fatalf("GetMulti failed: %s", err)
+	} else if len(ss) != 0 {
+		t.Errorf("Expected 0 sessions, got %d", len(ss))
+	}
+}
+
+func TestStore_Save_unchanged(t *testing.T) {
+	store := newSQLiteStore(t)
+
+	session := sessions.NewSession(store, "a")
+	session.Values().Set("foo", "bar")
+
+	if err := store.Save(httptest.NewRecorder(), session); err != nil {
+		t.Fatalf("Save failed: %s", err)
+	} else if session.IsDirty() {
+		t.Fatalf("Expected session to be clean after saving.")
+	}
+
+	// Delete the row behind the store’s back. Saving the unchanged session
+	// must not restore it.
+	if _, err := store.DB.Exec("DELETE FROM test_sessions"); err != nil {
+		t.Fatalf("Deleting sessions failed: %s", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	if err := store.Save(recorder, session); err != nil {
+		t.Fatalf("Save failed: %s", err)
+	} else if len(recorder.Result().Cookies()) != 0 {
+		t.Errorf("Expected no cookie for unchanged session.")
+	} else if ss, err := store.GetMulti(nil); err != nil {
+		t.Fatalf("GetMulti failed: %s", err)
+	} else if len(ss) != 0 {
+		t.Errorf("Expected unchanged session not to be saved.")
+	}
+
+	session.Values().Set("foo", "baz")
+	if err := store.Save(httptest.NewRecorder(), session); err != nil {
+		t.Fatalf("Save failed: %s", err)
+	} else if ss, err := store.GetMulti(nil); err != nil {
+		t.Fatalf("GetMulti failed: %s", err)
+	} else if len(ss) != 1 {
+		t.Errorf("Expected changed session to be saved.")
+	} else if ss[0].IsDirty() {
+		t.Errorf("Expected loaded session to be clean.")
+	}
+}
+
+func TestStore_Save_maxSessionsPerUser(t *testing.T) {
+	store := newSQLiteStore(t)
+	store.MaxSessionsPerUser = 2
+
+	save := func(id, userID string, day int) {
+		session := sessions.NewSession(store, id)
+		session.SetDateCreated(time.Date(2090, 1, day, 0, 0, 0, 0, time.UTC))
+		session.SetUserID(userID)
+		if err := store.Save(httptest.NewRecorder(), session); err != nil {
+			t.Fatalf("Save failed: %s", err)
+		}
+	}
+
+	save("a", "user1", 2)
+	save("b", "user1", 3)
+	save("c", "user2", 1)
+	save("d", "", 1)
+	save("e", "", 1)
+	save("f", "", 1)
+
+	// The saved session is kept even though it is the oldest one.
+	save("g", "user1", 1)
+
+	ss, err := store.GetMulti(nil)
+	if err != nil {
+		t.Fatalf("GetMulti failed: %s", err)
+	}
+
+	ids := make([]string, 0, len(ss))
+	for _, session := range ss {
+		ids = append(ids, session.ID())
+	}
+
+	if expected := []string{"c", "d", "e", "f", "g", "b"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected sessions %v, got %v", expected, ids)
+	}
+}
+
+func TestStore_ReadDB(t *testing.T) {
+	store := newSQLiteStore(t)
+	replica := newSQLiteStore(t)
+	store.ReadDB = replica.DB
+
+	if err := sessions.NewSession(store, "a").Save(httptest.NewRecorder()); err != nil {
+		t.Fatalf("Save failed: %s", err)
+	} else if err := sessions.NewSession(replica, "b").Save(httptest.NewRecorder()); err != nil {
+		t.Fatalf("Save failed: %s", err)
+	}
+
+	if ss, err := store.GetMulti(nil); err != nil {
+		t.Fatalf("GetMulti failed: %s", err)
+	} else if len(ss) != 1 || ss[0].ID() != "b" {
+		t.Errorf("Expected session to be read from ReadDB, got %v", ss)
+	}
+
+	store.ReadDB = nil
+	if ss, err := store.GetMulti(nil); err != nil {
+		t.Fatalf("GetMulti failed: %s", err)
+	} else if len(ss) != 1 || ss[0].ID() != "a" {
+		t.Errorf("Expected session to be read from DB, got %v", ss)
+	}
+}
+
+func TestStore_OnCreate_OnDelete(t *testing.T) {
+	store := newSQLiteStore(t)
+
+	var created, deleted []string
+	store.OnCreate = func(session sessions.Session) { created = append(created, session.ID()) }
+	store.OnDelete = func(sessionID string) { deleted = append(deleted, sessionID) }
+
+	session := sessions.NewSession(store, "a")
+	if err := session.Save(httptest.NewRecorder()); err != nil {
+		t.Fatalf("Save failed: %s", err)
+	}
+
+	session.Values().Set("foo", "bar")
+	if err := session.Save(httptest.NewRecorder()); err != nil {
+		t.Fatalf("Save failed: %s", err)
+	}
+
+	if err := session.Delete(httptest.NewRecorder()); err != nil {
+		t.Fatalf("Delete failed: %s", err)
+	}
+
+	if expected := []string{"a"}; !reflect.DeepEqual(created, expected) {
+		t.Errorf("Expected created %v, got %v", expected, created)
+	} else if !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("Expected deleted %v, got %v", expected, deleted)
+	}
+}
+
+func TestStore_DeleteExpired(t *testing.T) {
+	store := newSQLiteStore(t)
+	now := time.Date(2099, 12, 31, 13, 14, 15, 0, time.UTC)
+
+	for id, dateCreated := range map[string]time.Time{
+		"a": now.Add(-3 * time.Hour),
+		"b": now.Add(-2 * time.Hour),
+		"c": now,
+	} {
+		session := sessions.NewSession(store, id)
+		session.SetDateCreated(dateCreated)
+		if err := store.SaveMulti([]sessions.Session{session}); err != nil {
+			t.Fatalf("SaveMulti failed: %s", err)
+		}
+	}
+
+	tests := []struct {
+		before   time.Time
+		expected int
+	}{
+		{time.Time{}, 0},
+		{now.Add(-time.Hour), 2},
+		{now.Add(-time.Hour), 0},
+	}
+
+	for _, test := range tests {
+		count, err := store.DeleteExpired(test.before)
+		if err != nil {
+			t.Fatalf("DeleteExpired failed: %s", err)
+		} else if count != test.expected {
+			t.Errorf("Expected %d deleted sessions, got %d", test.expected, count)
+		}
+	}
+
+	result, err := store.GetMulti(nil)
+	if err != nil {
+		t.Fatalf("GetMulti failed: %s", err)
+	} else if len(result) != 1 || result[0].ID() != "c" {
+		t.Errorf("Expected session %q to remain, got %d sessions", "c", len(result))
+	}
+}
+
+func TestStore_DeleteExpiredEvery(t *testing.T) {
+	store := newSQLiteStore(t)
+	store.Expiration = time.Hour
+	now := time.Date(2099, 12, 31, 13, 14, 15, 0, time.UTC)
+	setNow(store, now)
+
+	for id, dateCreated := range map[string]time.Time{
+		"expired": now.Add(-2 * time.Hour),
+		"valid":   now.Add(-30 * time.Minute),
+	} {
+		session := sessions.NewSession(store, id)
+		session.SetDateCreated(dateCreated)
+		if err := store.SaveMulti([]sessions.Session{session}); err != nil {
+			t.Fatalf("SaveMulti failed: %s", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		store.DeleteExpiredEvery(ctx, time.Millisecond, func(err error) {
+			t.Errorf("Unexpected error: %s", err)
+		})
+		close(done)
+	}()
+
+	for i := 0; ; i++ {
+		result, err := store.GetMulti(nil)
+		if err != nil {
+			t.Fatalf("GetMulti failed: %s", err)
+		} else if len(result) == 1 {
+			if result[0].ID() != "valid" {
+				t.Errorf("Expected session %q to remain, got %q", "valid", result[0].ID())
+			}
+			break
+		} else if i == 100 {
+			t.Fatalf("Expected 1 session, got %d", len(result))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("Expected DeleteExpiredEvery to return after cancelation.")
+	}
+}
+
+func TestStore_DeleteExpiredEvery_invalidInterval(t *testing.T) {
+	store := newSQLiteStore(t)
+
+	var err error
+	store.DeleteExpiredEvery(context.Background(), 0, func(e error) { err = e })
+	if err == nil {
+		t.Error("Expected error for interval 0.")
+	}
+}
+
+func TestStore_RecordClient(t *testing.T) {
+	store := newSQLiteStore(t)
+	store.RecordClient = true
+
+	request := httptest.NewRequest("GET", "/", nil)
+	request.RemoteAddr = "192.0.2.1:1234"
+	request.Header.Set("User-Agent", "Test/1.0")
+
+	session, err := store.Get(httptest.NewRecorder(), request)
+	if err != nil {
+		t.Fatalf("Get failed: %s", err)
+	} else if ip := session.IPAddress(); ip != "192.0.2.1" {
+		t.Errorf("Expected IP address %q, got %q", "192.0.2.1", ip)
+	} else if userAgent := session.UserAgent(); userAgent != "Test/1.0" {
+		t.Errorf("Expected user agent %q, got %q", "Test/1.0", userAgent)
+	} else if session.IsDirty() {
+		t.Errorf("Expected session not to be dirty.")
+	}
+
+	store.RecordClient = false
+	if session, err := store.Get(httptest.NewRecorder(), request); err != nil {
+		t.Fatalf("Get failed: %s", err)
+	} else if session.IPAddress() != "" || session.UserAgent() != "" {
+		t.Errorf("Expected client not to be recorded, got %q, %q", session.IPAddress(), session.UserAgent())
+	}
+}
+
+func TestStore_isID(t *testing.T) {
+	store := newSQLiteStore(t)
+	store.Strength = 4
+
+	tests := []struct {
+		id       string
+		expected bool
+	}{
+		{"0123abcd", true},
+		{"0123abc", false},
+		{"0123abcde0", false},
+		{"0123ABCD", false},
+		{"0123abcg", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if result := store.isID(test.id); result != test.expected {
+			t.Errorf("Expected %t for %q, got %t", test.expected, test.id, result)
+		}
+	}
+
+	session, err := store.newSession()
+	if err != nil {
+		t.Fatalf("newSession failed: %s", err)
+	} else if !store.isID(session.ID()) {
+		t.Errorf("Expected generated ID %q to be valid", session.ID())
+	}
+}
+
+// base64Serializer wraps sessions.JSONSerializer and Base64 encodes its output.
+type base64Serializer struct {
+	sessions.JSONSerializer
+}
+
+func (b base64Serializer) MarshalValues(values map[string]string) ([]byte, error) {
+	data, err := b.JSONSerializer.MarshalValues(values)
+	return []byte(base64.StdEncoding.EncodeToString(data)), err
+}
+
+func (b base64Serializer) UnmarshalValues(data []byte) (map[string]string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, err
+	}
+	return b.JSONSerializer.UnmarshalValues(decoded)
+}
+
+func TestStore_Serializer(t *testing.T) {
+	store := newSQLiteStore(t)
+	store.Serializer = base64Serializer{}
+
+	session := sessions.NewSession(store, "a")
+	session.Values().Set("foo", "bar")
+	if err := store.SaveMulti([]sessions.Session{session}); err != nil {
+		t.Fatalf("SaveMulti failed: %s", err)
+	}
+
+	var data string
+	if err := store.DB.QueryRow("SELECT data FROM test_sessions WHERE id = ?", "a").Scan(&data); err != nil {
+		t.Fatalf("Querying data failed: %s", err)
+	} else if expected := base64.StdEncoding.EncodeToString([]byte(`{"foo":"bar"}`)); data != expected {
+		t.Errorf("Expected data %q, got %q", expected, data)
+	}
+
+	result, err := store.GetMulti(nil)
+	if err != nil {
+		t.Fatalf("GetMulti failed: %s", err)
+	} else if len(result) != 1 || result[0].Values().Get("foo") != "bar" {
+		t.Errorf("Expected session with value %q", "bar")
+	}
+}
+
+func TestStore_Reserialize(t *testing.T) {
+	store := newSQLiteStore(t)
+
+	var batch []sessions.Session
+	for _, id := range []string{"a", "b", "c"} {
+		session := sessions.NewSession(store, id)
+		session.Values().Set("foo", id)
+		batch = append(batch, session)
+	}
+	if err := store.SaveMulti(batch); err != nil {
+		t.Fatalf("SaveMulti failed: %s", err)
+	}
+
+	store.Serializer = base64Serializer{}
+	if _, err := store.GetMulti(nil); err == nil {
+		t.Fatal("Expected GetMulti to fail before Reserialize")
+	}
+
+	if count, err := store.Reserialize(sessions.JSONSerializer{}); err != nil {
+		t.Fatalf("Reserialize failed: %s", err)
+	} else if count != 3 {
+		t.Errorf("Expected 3 updated sessions, got %d", count)
+	}
+
+	result, err := store.GetMulti(nil)
+	if err != nil {
+		t.Fatalf("GetMulti failed: %s", err)
+	}
+	for _, session := range result {
+		if value := session.Values().Get("foo"); value != session.ID() {
+			t.Errorf("Expected value %q, got %q", session.ID(), value)
+		}
+	}
+
+	if count, err := store.Reserialize(sessions.JSONSerializer{}); err != nil {
+		t.Fatalf("Reserialize failed on second run: %s", err)
+	} else if count != 0 {
+		t.Errorf("Expected 0 updated sessions on second run, got %d", count)
+	}
+}
@@ -10,12 +10,13 @@ import (
 	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ChristianSiegert/go-packages/sessions"
@@ -24,11 +25,6 @@ import (
 // Pattern is the pattern used to match a session ID.
 var pattern = regexp.MustCompile("^[0-9a-f]+$")
 
-// KeyUserID is the key used to retrieve the user ID from session.Values and
-// store it in an indexed table column. This makes it possible to delete all
-// sessions of a particular user.
-var KeyUserID = "user.id"
-
 // authMethod is the method used to pass session IDs between server and client.
 type authMethod string
 
@@ -54,18 +50,54 @@ type Store struct {
 	// DB is the database in which the sessions table resides.
 	DB *sql.DB
 
+	// ReadDB, if not nil, is used instead of DB for reading sessions, e.g. a
+	// read replica of DB. Sessions are always written to DB.
+	ReadDB *sql.DB
+
 	// SQL dialect to use.
 	Dialect string
 
 	// Expiration is the duration after which sessions expire.
 	Expiration time.Duration
 
+	// MaxSessionsPerUser is the maximum number of sessions a user can have. If
+	// Save saves a session of a user who then has more sessions, the user’s
+	// oldest other sessions are deleted. 0 means unlimited.
+	MaxSessionsPerUser int
+
+	// OnCreate, if not nil, is called after Save saved a session that was not
+	// stored before, e.g. for audit logging.
+	OnCreate func(session sessions.Session)
+
+	// OnDelete, if not nil, is called after Delete deleted a session. It is
+	// not called by DeleteMulti.
+	OnDelete func(sessionID string)
+
+	// RecordClient determines whether the IP address and user agent of the
+	// client are recorded when Get creates a new session, see
+	// sessions.Session.SetClient. The IP address is taken from
+	// http.Request.RemoteAddr, so behind a proxy, RemoteAddr must be set to
+	// the client’s address before Get is called.
+	RecordClient bool
+
+	// Serializer encodes and decodes the flashes and values of sessions. The
+	// encoding must be text, because they are saved in text columns. If
+	// Serializer is nil, sessions.JSONSerializer is used.
+	Serializer sessions.Serializer
+
 	// Strength is the number of bytes to use for generating a session ID. The
-	// higher the number, the more secure the session ID.
+	// higher the number, the more secure the session ID. Session IDs are hex
+	// encoded, so they are twice as long. Get rejects session IDs of other
+	// lengths without querying the database, so changing Strength ends
+	// existing sessions.
 	Strength int
 
 	// TableName is the name of the sessions table.
 	TableName string
+
+	// clock returns the current time. Tests replace it to control session
+	// and cookie dates. If it is nil, time.Now is used.
+	clock func() time.Time
 }
 
 // AuthOptions is the authentification configuration for the store. If
@@ -79,6 +111,11 @@ type AuthOptions struct {
 	CookieName   string
 	CookiePath   string
 
+	// CookieSameSite is the cookie’s SameSite attribute. Together with a CSRF
+	// token, see sessions.VerifyCSRFToken, it protects against cross-site
+	// request forgery.
+	CookieSameSite http.SameSite
+
 	// HeaderName is the name of the request header that is used to pass the
 	// session ID.
 	HeaderName string
@@ -96,9 +133,10 @@ func New(dialect string, db *sql.DB, tableName string) (*Store, error) {
 	}
 
 	authOptions := AuthOptions{
-		AuthMethod: AuthMethodCookie,
-		CookieName: "session",
-		CookiePath: "/",
+		AuthMethod:     AuthMethodCookie,
+		CookieName:     "session",
+		CookiePath:     "/",
+		CookieSameSite: http.SameSiteLaxMode,
 	}
 
 	store := &Store{
@@ -106,6 +144,7 @@ func New(dialect string, db *sql.DB, tableName string) (*Store, error) {
 		DB:          db,
 		Dialect:     dialect,
 		Expiration:  30 * 24 * time.Hour,
+		Serializer:  sessions.JSONSerializer{},
 		Strength:    40,
 		TableName:   tableName,
 	}
@@ -137,20 +176,20 @@ func (s *Store) Delete(writer http.ResponseWriter, sessionID string) error {
 	if s.AuthOptions.AuthMethod == AuthMethodCookie {
 		s.deleteCookie(writer)
 	}
+
+	if s.OnDelete != nil {
+		s.OnDelete(sessionID)
+	}
 	return nil
 }
 
 // DeleteMulti deletes sessions from the store that match the criteria specified
 // in filter. If no criterion is specified, all sessions are deleted.
 func (s *Store) DeleteMulti(filter *sessions.Filter) error {
-	if filter != nil {
-		return errors.New("filter not implemented")
-	}
-
-	query := "DELETE FROM %s"
-	query = fmt.Sprintf(query, s.TableName)
+	where, args := s.where(filter)
+	query := fmt.Sprintf(queries[s.Dialect][queryDeleteMulti], s.TableName) + where
 
-	_, err := s.DB.Exec(query)
+	_, err := s.DB.Exec(query, args...)
 	return err
 }
 
@@ -164,20 +203,20 @@ func (s *Store) Get(writer http.ResponseWriter, request *http.Request) (sessions
 		cookie, err := request.Cookie(s.AuthOptions.CookieName)
 
 		if err == http.ErrNoCookie {
-			return s.newSession()
+			return s.newClientSession(request)
 		} else if err != nil {
 			return nil, err
-		} else if !isID(cookie.Value) {
+		} else if !s.isID(cookie.Value) {
 			s.deleteCookie(writer)
-			return s.newSession()
+			return s.newClientSession(request)
 		}
 		sessionID = cookie.Value
 	case AuthMethodHeader:
 		sessionID = request.Header.Get(s.AuthOptions.HeaderName)
 	}
 
-	if !isID(sessionID) {
-		return s.newSession()
+	if !s.isID(sessionID) {
+		return s.newClientSession(request)
 	}
 
 	session := sessions.NewSession(s, sessionID)
@@ -192,7 +231,7 @@ func (s *Store) Get(writer http.ResponseWriter, request *http.Request) (sessions
 	}{}
 
 	query := fmt.Sprintf(queries[s.Dialect][queryGet], s.TableName)
-	row := s.DB.QueryRow(query, session.ID())
+	row := s.readDB().QueryRow(query, session.ID())
 
 	err := row.Scan(
 		&temp.encodedValues,
@@ -202,40 +241,74 @@ func (s *Store) Get(writer http.ResponseWriter, request *http.Request) (sessions
 	)
 	if err == sql.ErrNoRows {
 		s.deleteCookie(writer)
-		return s.newSession()
+		return s.newClientSession(request)
 	} else if err != nil {
 		return nil, err
 	}
 
-	session.SetDateCreated(temp.dateCreated)
-	session.SetIsStored(true)
-
-	// Decode flashes
-	flashes, err := sessions.FlashesFromJSON(temp.encodedFlashes)
-	if err != nil {
+	if err := s.decode(session, temp.dateCreated, temp.encodedFlashes, temp.encodedValues); err != nil {
 		return nil, err
 	}
-	session.Flashes().Add(flashes...)
+	return session, nil
+}
 
-	// Decode values
-	values, err := sessions.ValuesFromJSON(temp.encodedValues)
+// GetMulti gets sessions from the store that match the criteria specified in
+// filter. If no criterion is specified, all sessions are returned.
+func (s *Store) GetMulti(filter *sessions.Filter) ([]sessions.Session, error) {
+	where, args := s.where(filter)
+	query := fmt.Sprintf(queries[s.Dialect][queryGetMulti], s.TableName) + where + " ORDER BY date_created, id"
+
+	rows, err := s.readDB().Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
-	session.Values().SetAll(values)
+	defer rows.Close()
+
+	var ss []sessions.Session
+
+	for rows.Next() {
+		temp := struct {
+			dateCreated    time.Time
+			encodedFlashes []byte
+			encodedValues  []byte
+			id             string
+		}{}
+
+		if err := rows.Scan(
+			&temp.encodedValues,
+			&temp.dateCreated,
+			&temp.encodedFlashes,
+			&temp.id,
+		); err != nil {
+			return nil, err
+		}
 
-	return session, nil
+		session := sessions.NewSession(s, temp.id)
+		if err := s.decode(session, temp.dateCreated, temp.encodedFlashes, temp.encodedValues); err != nil {
+			return nil, err
+		}
+		ss = append(ss, session)
+	}
+
+	return ss, rows.Err()
 }
 
-// GetMulti gets sessions from the store that match the criteria specified in
-// filter.
-func (s *Store) GetMulti(filter *sessions.Filter) ([]sessions.Session, error) {
-	return nil, errors.New("method not implemented")
+// readDB returns the database for reading sessions.
+func (s *Store) readDB() *sql.DB {
+	if s.ReadDB != nil {
+		return s.ReadDB
+	}
+	return s.DB
 }
 
 // Save saves a session to the store. If s.AuthOptions.AuthMethod is
-// AuthMethodCookie, it creates or updates the session cookie.
+// AuthMethodCookie, it creates or updates the session cookie. If the session
+// is already stored and was not changed, Save does nothing.
 func (s *Store) Save(writer http.ResponseWriter, session sessions.Session) error {
+	if session.IsStored() && !session.IsDirty() {
+		return nil
+	}
+
 	switch s.AuthOptions.AuthMethod {
 	case AuthMethodCookie:
 		s.saveCookie(writer, session)
@@ -245,34 +318,62 @@ func (s *Store) Save(writer http.ResponseWriter, session sessions.Session) error
 
 	query := fmt.Sprintf(queries[s.Dialect][querySave], s.TableName)
 
-	encodedFlashes, err := json.Marshal(session.Flashes().GetAll())
+	args, err := s.encode(session)
 	if err != nil {
 		return err
 	}
 
-	encodedValues, err := json.Marshal(session.Values().GetAll())
-	if err != nil {
+	if s.MaxSessionsPerUser > 0 && session.UserID() != "" {
+		if err := s.saveLimited(query, args, session); err != nil {
+			return err
+		}
+	} else if _, err := s.DB.Exec(query, args...); err != nil {
 		return err
 	}
 
-	_, err = s.DB.Exec(
-		query,
-		encodedValues,
-		session.DateCreated(),
-		encodedFlashes,
-		session.ID(),
-		session.Values().Get(KeyUserID),
-	)
+	isNew := !session.IsStored()
+	session.SetIsDirty(false)
+	session.SetIsStored(true)
 
+	if isNew && s.OnCreate != nil {
+		s.OnCreate(session)
+	}
+	return nil
+}
+
+// saveLimited saves session by executing query with args, and deletes the
+// oldest sessions of the session’s user that exceed MaxSessionsPerUser. Both
+// happen within a single transaction.
+func (s *Store) saveLimited(query string, args []interface{}, session sessions.Session) (e error) {
+	tx, err := s.DB.Begin()
 	if err != nil {
 		return err
 	}
 
-	session.SetIsStored(true)
-	return nil
+	// If tx was not committed, rollback. If rollback fails, return rollback’s
+	// error instead of the original error.
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			e = err
+		}
+	}()
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		return err
+	}
+
+	limitQuery := fmt.Sprintf(queries[s.Dialect][queryLimitUser], s.TableName, s.TableName)
+	if _, err := tx.Exec(limitQuery, session.UserID(), session.ID(), s.MaxSessionsPerUser-1); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// SaveMulti saves the provided sessions.
+// SaveMulti saves the provided sessions within a single transaction. If the
+// dialect is PostgreSQL and at least minBatchSize sessions are provided, the
+// sessions are saved in batches, using one statement per batch. After the
+// transaction was committed, the sessions are marked as stored and clean.
 func (s *Store) SaveMulti(sessions []sessions.Session) (e error) {
 	tx, err := s.DB.Begin()
 	if err != nil {
@@ -287,6 +388,13 @@ func (s *Store) SaveMulti(sessions []sessions.Session) (e error) {
 		}
 	}()
 
+	if s.Dialect == DialectPostgreSQL && len(sessions) >= minBatchSize {
+		if err := s.saveBatches(tx, sessions); err != nil {
+			return err
+		}
+		return commitSaved(tx, sessions)
+	}
+
 	query := fmt.Sprintf(queries[s.Dialect][querySave], s.TableName)
 	statement, err := tx.Prepare(query)
 	if err != nil {
@@ -294,30 +402,166 @@ func (s *Store) SaveMulti(sessions []sessions.Session) (e error) {
 	}
 
 	for _, session := range sessions {
-		encodedFlashes, err := json.Marshal(session.Flashes().GetAll())
+		args, err := s.encode(session)
 		if err != nil {
 			return err
 		}
 
-		encodedValues, err := json.Marshal(session.Values().GetAll())
-		if err != nil {
+		if _, err := statement.Exec(args...); err != nil {
 			return err
 		}
+	}
 
-		_, err = statement.Exec(
-			encodedValues,
-			session.DateCreated(),
-			encodedFlashes,
-			session.ID(),
-			session.Values().Get(KeyUserID),
-		)
+	return commitSaved(tx, sessions)
+}
 
-		if err != nil {
+// commitSaved commits tx, in which ss were saved, and marks ss as stored and
+// clean. If committing fails, ss are left unchanged.
+func commitSaved(tx *sql.Tx, ss []sessions.Session) error {
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, session := range ss {
+		session.SetIsDirty(false)
+		session.SetIsStored(true)
+	}
+	return nil
+}
+
+// Limits for saving sessions in batches with SaveMulti.
+const (
+	// minBatchSize is the minimum number of sessions for which batches are
+	// used. Fewer sessions are saved one by one.
+	minBatchSize = 10
+
+	// maxBatchSize is the maximum number of sessions per batch. PostgreSQL
+	// supports at most 65535 arguments per statement, and each session needs
+	// five.
+	maxBatchSize = 1000
+)
+
+// saveBatches saves sessions within tx, using one multi-row upsert statement
+// per batch of at most maxBatchSize sessions. If several sessions have the
+// same ID, only the last one is saved, which has the same result as saving
+// the sessions one by one.
+func (s *Store) saveBatches(tx *sql.Tx, ss []sessions.Session) error {
+	ss = withUniqueIDs(ss)
+
+	for start := 0; start < len(ss); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(ss) {
+			end = len(ss)
+		}
+		batch := ss[start:end]
+
+		args := make([]interface{}, 0, 5*len(batch))
+		for _, session := range batch {
+			sessionArgs, err := s.encode(session)
+			if err != nil {
+				return err
+			}
+			args = append(args, sessionArgs...)
+		}
+
+		if _, err := tx.Exec(s.saveBatchQuery(len(batch)), args...); err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
-	return tx.Commit()
+// saveBatchQuery returns the query for saving count sessions with a single
+// statement.
+func (s *Store) saveBatchQuery(count int) string {
+	rows := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		rows = append(rows, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", 5*i+1, 5*i+2, 5*i+3, 5*i+4, 5*i+5))
+	}
+	return fmt.Sprintf(queries[s.Dialect][querySaveBatch], s.TableName, strings.Join(rows, ", "))
+}
+
+// withUniqueIDs returns ss without sessions whose ID occurs again later in ss.
+func withUniqueIDs(ss []sessions.Session) []sessions.Session {
+	last := make(map[string]int, len(ss))
+	for i, session := range ss {
+		last[session.ID()] = i
+	}
+
+	if len(last) == len(ss) {
+		return ss
+	}
+
+	unique := make([]sessions.Session, 0, len(last))
+	for i, session := range ss {
+		if last[session.ID()] == i {
+			unique = append(unique, session)
+		}
+	}
+	return unique
+}
+
+// where returns an SQL WHERE clause and its arguments that limit a query to
+// sessions matching filter. If filter is nil or specifies no criterion, where
+// returns an empty clause.
+func (s *Store) where(filter *sessions.Filter) (string, []interface{}) {
+	if filter == nil {
+		return "", nil
+	}
+
+	var args []interface{}
+	var conditions []string
+
+	// in returns a condition that checks whether column has one of values.
+	in := func(column string, values []string) string {
+		placeholders := make([]string, 0, len(values))
+		for _, value := range values {
+			args = append(args, value)
+			placeholders = append(placeholders, s.placeholder(len(args)))
+		}
+		return column + " IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+
+	// compare returns a condition that compares column with value.
+	compare := func(column, operator string, value time.Time) string {
+		args = append(args, value)
+		return column + " " + operator + " " + s.placeholder(len(args))
+	}
+
+	var idConditions []string
+	if len(filter.IDs) > 0 {
+		idConditions = append(idConditions, in("id", filter.IDs))
+	}
+	if len(filter.UserIDs) > 0 {
+		idConditions = append(idConditions, in("user_id", filter.UserIDs))
+	}
+	if len(idConditions) > 0 {
+		conditions = append(conditions, "("+strings.Join(idConditions, " OR ")+")")
+	}
+
+	var dateConditions []string
+	if !filter.DateCreatedBefore.IsZero() {
+		dateConditions = append(dateConditions, compare("date_created", "<", filter.DateCreatedBefore))
+	}
+	if !filter.DateCreatedAfter.IsZero() {
+		dateConditions = append(dateConditions, compare("date_created", ">", filter.DateCreatedAfter))
+	}
+	if len(dateConditions) > 0 {
+		conditions = append(conditions, "("+strings.Join(dateConditions, " OR ")+")")
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// placeholder returns the dialect’s placeholder for the nth query argument.
+func (s *Store) placeholder(n int) string {
+	if s.Dialect == DialectPostgreSQL {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
 }
 
 // newSession returns a new session with a randomly generated ID.
@@ -326,7 +570,30 @@ func (s *Store) newSession() (sessions.Session, error) {
 	if err != nil {
 		return nil, err
 	}
-	return sessions.NewSession(s, id), nil
+	session := sessions.NewSession(s, id)
+	session.SetDateCreated(s.now())
+	session.SetIsDirty(false)
+	return session, nil
+}
+
+// newClientSession returns a new session like newSession. If s.RecordClient
+// is true, the IP address and user agent of request’s client are recorded.
+// They do not mark the session as dirty, so recording them alone does not
+// cause the session to be saved.
+func (s *Store) newClientSession(request *http.Request) (sessions.Session, error) {
+	session, err := s.newSession()
+	if err != nil || !s.RecordClient {
+		return session, err
+	}
+
+	ipAddress := request.RemoteAddr
+	if host, _, err := net.SplitHostPort(request.RemoteAddr); err == nil {
+		ipAddress = host
+	}
+
+	session.SetClient(ipAddress, request.UserAgent())
+	session.SetIsDirty(false)
+	return session, nil
 }
 
 func (s *Store) saveCookie(writer http.ResponseWriter, session sessions.Session) {
@@ -336,9 +603,10 @@ func (s *Store) saveCookie(writer http.ResponseWriter, session sessions.Session)
 		Domain:   s.AuthOptions.CookieDomain,
 		Expires:  dateExpires,
 		HttpOnly: true,
-		MaxAge:   int(dateExpires.Sub(time.Now()).Seconds()),
+		MaxAge:   int(dateExpires.Sub(s.now()).Seconds()),
 		Name:     s.AuthOptions.CookieName,
 		Path:     s.AuthOptions.CookiePath,
+		SameSite: s.AuthOptions.CookieSameSite,
 		Value:    session.ID(),
 	})
 }
@@ -346,15 +614,79 @@ func (s *Store) saveCookie(writer http.ResponseWriter, session sessions.Session)
 func (s *Store) deleteCookie(writer http.ResponseWriter) {
 	http.SetCookie(writer, &http.Cookie{
 		Domain:   s.AuthOptions.CookieDomain,
-		Expires:  time.Now().Add(-24 * time.Hour),
+		Expires:  s.now().Add(-24 * time.Hour),
 		HttpOnly: true,
 		MaxAge:   -1,
 		Name:     s.AuthOptions.CookieName,
 		Path:     s.AuthOptions.CookiePath,
+		SameSite: s.AuthOptions.CookieSameSite,
 	})
 }
 
-// generateID generates a session ID and encodes it in Base64.
+// encode returns the arguments for saving session with the querySave query:
+// the encoded values, the creation date, the encoded flashes, the ID and the
+// user ID.
+func (s *Store) encode(session sessions.Session) ([]interface{}, error) {
+	encodedFlashes, err := s.serializer().MarshalFlashes(session.Flashes().GetAll())
+	if err != nil {
+		return nil, err
+	}
+
+	encodedValues, err := s.serializer().MarshalValues(session.Values().GetAll())
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{
+		encodedValues,
+		session.DateCreated(),
+		encodedFlashes,
+		session.ID(),
+		session.UserID(),
+	}, nil
+}
+
+// decode sets the session’s creation date and marks it as stored and
+// unchanged, and adds the encoded flashes and values to the session.
+func (s *Store) decode(session sessions.Session, dateCreated time.Time, encodedFlashes, encodedValues []byte) error {
+	session.SetDateCreated(dateCreated)
+	session.SetIsStored(true)
+
+	// Decode flashes
+	flashes, err := s.serializer().UnmarshalFlashes(encodedFlashes)
+	if err != nil {
+		return err
+	}
+	session.Flashes().Add(flashes...)
+
+	// Decode values
+	values, err := s.serializer().UnmarshalValues(encodedValues)
+	if err != nil {
+		return err
+	}
+	session.Values().SetAll(values)
+	session.SetIsDirty(false)
+	return nil
+}
+
+// now returns the current time, see clock.
+func (s *Store) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
+	}
+	return s.clock()
+}
+
+// serializer returns s.Serializer, or sessions.JSONSerializer if it is nil.
+func (s *Store) serializer() sessions.Serializer {
+	if s.Serializer == nil {
+		return sessions.JSONSerializer{}
+	}
+	return s.Serializer
+}
+
+// generateID generates a session ID of strength random bytes and encodes it
+// in hex.
 func generateID(strength int) (string, error) {
 	id := make([]byte, strength)
 
@@ -364,7 +696,8 @@ func generateID(strength int) (string, error) {
 	return hex.EncodeToString(id), nil
 }
 
-// isID checks whether id is a valid session ID.
-func isID(id string) bool {
-	return pattern.MatchString(id)
+// isID checks whether id is a valid session ID, i.e. a hex encoded string of
+// s.Strength bytes.
+func (s *Store) isID(id string) bool {
+	return len(id) == hex.EncodedLen(s.Strength) && pattern.MatchString(id)
 }
@@ -6,9 +6,9 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/http/httptest"
-	"os"
-	"path"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -21,7 +21,14 @@ import (
 
 var dateCreated = time.Date(2099, 12, 31, 13, 14, 15, 0, time.Local)
 
-func setUp(dialect dialect) (*sql.DB, sessions.Store, error) {
+// sessionID is the ID of the session saved by testSave. It is a valid ID for
+// the default Strength.
+var sessionID = strings.Repeat("0123456789", 8)
+
+// setUp returns a store of the provided dialect. If the dialect’s database is
+// not available, e.g. because no PostgreSQL server is running, the test is
+// skipped.
+func setUp(t *testing.T, dialect string) (*sql.DB, sessions.Store) {
 	var db *sql.DB
 	var err error
 	const tableName = "test_sessions"
@@ -30,25 +37,19 @@ func setUp(dialect dialect) (*sql.DB, sessions.Store, error) {
 	case DialectPostgreSQL:
 		db, err = setUpPostgres(tableName)
 	case DialectSQLite:
-		db, err = setUpSQLite()
+		db, err = setUpSQLite(t.TempDir())
 	}
 
 	if err != nil {
-		return nil, nil, err
-	}
-
-	// Create store instance
-	authOptions := AuthOptions{
-		AuthMethod: AuthMethodCookie,
-		CookieName: "session",
+		t.Skipf("Database %q not available: %s", dialect, err)
 	}
 
-	store, err := New(db, tableName, dialect, authOptions)
+	store, err := New(dialect, db, tableName)
 	if err != nil {
 		db.Close()
-		return nil, nil, fmt.Errorf("Creating store failed:%s", err)
+		t.Fatalf("Creating store failed: %s", err)
 	}
-	return db, store, nil
+	return db, store
 }
 
 func setUpPostgres(tableName string) (*sql.DB, error) {
@@ -61,6 +62,11 @@ func setUpPostgres(tableName string) (*sql.DB, error) {
 		return nil, fmt.Errorf("Opening database failed: %s", err)
 	}
 
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	// Delete table
 	query := fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName)
 	_, err = db.Exec(query)
@@ -70,13 +76,8 @@ func setUpPostgres(tableName string) (*sql.DB, error) {
 	return db, nil
 }
 
-func setUpSQLite() (*sql.DB, error) {
-	filename := path.Join(os.TempDir(), "test.sqlite")
-
-	// Delete previous database file
-	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
-		return nil, fmt.Errorf("Removing database file failed: %s", err)
-	}
+func setUpSQLite(dir string) (*sql.DB, error) {
+	filename := filepath.Join(dir, "test.sqlite")
 
 	// Open database
 	db, err := sql.Open("sqlite3", filename)
@@ -91,16 +92,15 @@ func tearDown(db *sql.DB) {
 }
 
 func Test(t *testing.T) {
-	for _, dialect := range []dialect{DialectPostgreSQL, DialectSQLite} {
-		test(dialect, t)
+	for _, dialect := range []string{DialectPostgreSQL, DialectSQLite} {
+		t.Run(dialect, func(t *testing.T) {
+			test(t, dialect)
+		})
 	}
 }
 
-func test(dialect dialect, t *testing.T) {
-	db, store, err := setUp(dialect)
-	if err != nil {
-		t.Error(err)
-	}
+func test(t *testing.T, dialect string) {
+	db, store := setUp(t, dialect)
 	defer tearDown(db)
 
 	// Create routes
@@ -138,7 +138,7 @@ func test(dialect dialect, t *testing.T) {
 }
 
 func testSave(writer http.ResponseWriter, request *http.Request, t *testing.T, store sessions.Store) {
-	session := sessions.NewSession(store, "session123")
+	session := sessions.NewSession(store, sessionID)
 	session.SetDateCreated(dateCreated)
 	session.Flashes().AddNew("lorem ipsum", "info")
 	session.Values().Set("user.id", "user1")
@@ -153,7 +153,7 @@ func testSave(writer http.ResponseWriter, request *http.Request, t *testing.T, s
 }
 
 func testGet(writer http.ResponseWriter, request *http.Request, t *testing.T, store sessions.Store) {
-	expectedSession := sessions.NewSession(store, "session123")
+	expectedSession := sessions.NewSession(store, sessionID)
 	expectedSession.SetDateCreated(dateCreated)
 	expectedSession.Flashes().AddNew("lorem ipsum", "info")
 	expectedSession.Values().Set("user.id", "user1")
@@ -163,46 +163,45 @@ func testGet(writer http.ResponseWriter, request *http.Request, t *testing.T, st
 		t.Errorf("Getting session failed: %s", err)
 	} else if !session.DateCreated().Equal(expectedSession.DateCreated()) {
 		t.Errorf("Expected DateCreated %q, got %q.", session.DateCreated(), expectedSession.DateCreated())
-	} else if !reflect.DeepEqual(session.Flashes(), expectedSession.Flashes()) {
-		t.Errorf("Expected Flashes %#v, got %#v", expectedSession.Flashes(), session.Flashes())
+	} else if !reflect.DeepEqual(session.Flashes().GetAll(), expectedSession.Flashes().GetAll()) {
+		t.Errorf("Expected Flashes %#v, got %#v", expectedSession.Flashes().GetAll(), session.Flashes().GetAll())
 	} else if session.ID() != expectedSession.ID() {
 		t.Errorf("Expected ID %q, got %q.", expectedSession.ID(), session.ID())
 	} else if !session.IsStored() {
 		t.Errorf("Expected session.IsStored() to be true, is false.")
-	} else if !reflect.DeepEqual(session.Values(), expectedSession.Values()) {
-		t.Errorf("Expected Values %#v, got %#v", expectedSession.Values(), session.Values())
+	} else if !reflect.DeepEqual(session.Values().GetAll(), expectedSession.Values().GetAll()) {
+		t.Errorf("Expected Values %#v, got %#v", expectedSession.Values().GetAll(), session.Values().GetAll())
 	}
 }
 
 func testDelete(writer http.ResponseWriter, request *http.Request, t *testing.T, store sessions.Store) {
-	if err := store.Delete(writer, "session123"); err != nil {
+	if err := store.Delete(writer, sessionID); err != nil {
 		t.Errorf("Deleting session failed: %s", err)
 	}
 
 	if session, err := store.Get(writer, request); err != nil {
 		t.Errorf("Getting session failed: %s", err)
-	} else if session.ID() == "session123" {
+	} else if session.ID() == sessionID {
 		t.Errorf("Expected random session ID, got old session ID %q.", session.ID())
 	}
 }
 
 func TestMulti(t *testing.T) {
-	for _, dialect := range []dialect{DialectPostgreSQL, DialectSQLite} {
-		testMulti(dialect, t)
+	for _, dialect := range []string{DialectPostgreSQL, DialectSQLite} {
+		t.Run(dialect, func(t *testing.T) {
+			testMulti(t, dialect)
+		})
 	}
 }
 
-func testMulti(dialect dialect, t *testing.T) {
-	db, store, err := setUp(dialect)
-	if err != nil {
-		t.Error(err)
-	}
+func testMulti(t *testing.T, dialect string) {
+	db, store := setUp(t, dialect)
 	defer tearDown(db)
 
 	sessionA := sessions.NewSession(store, "a")
 	sessionA.Flashes().AddNew("lorem", "ipsum")
 	sessionA.SetDateCreated(time.Date(2090, 11, 10, 9, 8, 7, 6, &time.Location{}))
-	sessionA.Values().Set(KeyUserID, "user-a")
+	sessionA.SetUserID("user-a")
 
 	ss := []sessions.Session{
 		sessionA,
@@ -217,8 +216,26 @@ func testMulti(dialect dialect, t *testing.T) {
 	ss2, err := store.GetMulti(nil)
 	if err != nil {
 		t.Errorf("GetMulti failed: %s", err)
-	} else if !reflect.DeepEqual(ss2, ss) {
-		t.Errorf("Expected sessions %#v, got %#v", ss, ss2)
+	} else if len(ss2) != len(ss) {
+		t.Errorf("Expected %d sessions, got %d", len(ss), len(ss2))
+	} else {
+		saved := make(map[string]sessions.Session, len(ss2))
+		for _, session := range ss2 {
+			saved[session.ID()] = session
+		}
+
+		for _, expected := range ss {
+			session, ok := saved[expected.ID()]
+			if !ok {
+				t.Errorf("Expected session %q", expected.ID())
+			} else if !session.DateCreated().Equal(expected.DateCreated()) {
+				t.Errorf("Expected DateCreated %s of session %q, got %s", expected.DateCreated(), expected.ID(), session.DateCreated())
+			} else if !reflect.DeepEqual(session.Flashes().GetAll(), expected.Flashes().GetAll()) {
+				t.Errorf("Expected Flashes %#v of session %q, got %#v", expected.Flashes().GetAll(), expected.ID(), session.Flashes().GetAll())
+			} else if session.UserID() != expected.UserID() {
+				t.Errorf("Expected user ID %q of session %q, got %q", expected.UserID(), expected.ID(), session.UserID())
+			}
+		}
 	}
 
 	if err := store.DeleteMulti(nil); err != nil {
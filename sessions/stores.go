@@ -10,6 +10,10 @@ type Store interface {
 	// Delete deletes a session from the store, and deletes the session cookie.
 	Delete(writer http.ResponseWriter, sessionID string) error
 
+	// DeleteExpired deletes sessions from the store that were created before
+	// before, and returns the number of deleted sessions.
+	DeleteExpired(before time.Time) (int, error)
+
 	// DeleteMulti deletes sessions from the store that match the criteria
 	// specified in filter.
 	DeleteMulti(filter *Filter) error
@@ -23,13 +27,26 @@ type Store interface {
 	GetMulti(filter *Filter) ([]Session, error)
 
 	// Save saves a session to the store and creates / updates the session
-	// cookie.
+	// cookie. After saving, it marks the session as clean with
+	// SetIsDirty(false). If the session is stored and not dirty, Save should
+	// do nothing, so programs can call Save once at the end of every request
+	// instead of after each change.
 	Save(http.ResponseWriter, Session) error
 
-	// SaveMulti saves the provided sessions.
+	// SaveMulti saves the provided sessions. If it succeeds, it marks them as
+	// stored and clean, like Save. Unlike Save, it does not set cookies.
 	SaveMulti([]Session) error
 }
 
+// PurgeInactive deletes the sessions of store that were created more than
+// maxAge ago, and returns the number of deleted sessions. It calls
+// Store.DeleteExpired, so it works with any store, e.g. periodically for
+// housekeeping. Stores do not track when a session was last accessed, so a
+// session’s age is measured from its creation date.
+func PurgeInactive(store Store, maxAge time.Duration) (int, error) {
+	return store.DeleteExpired(time.Now().Add(-maxAge))
+}
+
 // Filter is used to limit DeleteMulti and GetMulti to sessions that match the
 // criteria. Sessions match when 1) they have an ID or userID that is specified
 // in IDs or UserIDs, and 2) their DateCreated is before DateCreatedBefore or
@@ -0,0 +1,34 @@
+package sessions_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ChristianSiegert/go-packages/sessions"
+	"github.com/ChristianSiegert/go-packages/sessions/sessionstest"
+)
+
+func TestPurgeInactive(t *testing.T) {
+	store := sessionstest.New()
+	now := time.Now()
+
+	for id, age := range map[string]time.Duration{
+		"a": 3 * time.Hour,
+		"b": 2 * time.Hour,
+		"c": time.Minute,
+	} {
+		session := sessions.NewSession(store, id)
+		session.SetDateCreated(now.Add(-age))
+		if err := store.SaveMulti([]sessions.Session{session}); err != nil {
+			t.Fatalf("SaveMulti failed: %s", err)
+		}
+	}
+
+	if count, err := sessions.PurgeInactive(store, time.Hour); err != nil {
+		t.Fatalf("PurgeInactive failed: %s", err)
+	} else if count != 2 {
+		t.Errorf("Expected 2 deleted sessions, got %d", count)
+	} else if store.Len() != 1 {
+		t.Errorf("Expected 1 remaining session, got %d", store.Len())
+	}
+}
@@ -11,6 +11,10 @@ type Values interface {
 	// GetAll returns all keys and their associated value.
 	GetAll() map[string]string
 
+	// GetJSON JSON decodes the value associated with key into dest. If there
+	// is no value associated with key, dest is left unchanged.
+	GetJSON(key string, dest interface{}) error
+
 	// Remove removes values associated with the provided keys.
 	Remove(keys ...string)
 
@@ -22,6 +26,10 @@ type Values interface {
 
 	// SetAll sets all provided keys to their associated value.
 	SetAll(map[string]string)
+
+	// SetJSON sets the key to the JSON encoding of value. This allows storing
+	// structured data, which can be retrieved with GetJSON.
+	SetJSON(key string, value interface{}) error
 }
 
 // values is an unexported type that implements the Values interface.
@@ -46,6 +54,16 @@ func (v values) GetAll() map[string]string {
 	return map[string]string(v)
 }
 
+// GetJSON JSON decodes the value associated with key into dest. If there is no
+// value associated with key, dest is left unchanged.
+func (v values) GetJSON(key string, dest interface{}) error {
+	value, ok := v[key]
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal([]byte(value), dest)
+}
+
 // Remove removes values associated with the keys.
 func (v values) Remove(keys ...string) {
 	for _, key := range keys {
@@ -72,6 +90,56 @@ func (v values) SetAll(pairs map[string]string) {
 	}
 }
 
+// SetJSON sets the key to the JSON encoding of value.
+func (v values) SetJSON(key string, value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	v.Set(key, string(b))
+	return nil
+}
+
+// trackedValues wraps Values to mark the session as dirty when values are
+// changed.
+type trackedValues struct {
+	Values
+	session *session
+}
+
+// Remove removes values associated with the keys.
+func (t *trackedValues) Remove(keys ...string) {
+	t.Values.Remove(keys...)
+	t.session.isDirty = true
+}
+
+// RemoveAll removes all keys and values.
+func (t *trackedValues) RemoveAll() {
+	t.Values.RemoveAll()
+	t.session.isDirty = true
+}
+
+// Set sets the key to value. It replaces an existing value.
+func (t *trackedValues) Set(key, value string) {
+	t.Values.Set(key, value)
+	t.session.isDirty = true
+}
+
+// SetAll sets all provided keys to their associated value.
+func (t *trackedValues) SetAll(pairs map[string]string) {
+	t.Values.SetAll(pairs)
+	t.session.isDirty = true
+}
+
+// SetJSON sets the key to the JSON encoding of value.
+func (t *trackedValues) SetJSON(key string, value interface{}) error {
+	if err := t.Values.SetJSON(key, value); err != nil {
+		return err
+	}
+	t.session.isDirty = true
+	return nil
+}
+
 // ValuesFromJSON JSON decodes a map of key-value pairs. The result can be used
 // as input for Values.SetAll.
 func ValuesFromJSON(data []byte) (map[string]string, error) {
@@ -49,6 +49,40 @@ func TestValues_SetAll(t *testing.T) {
 	}
 }
 
+func TestValues_SetJSON(t *testing.T) {
+	type Cart struct {
+		Items    []string `json:"items"`
+		Quantity int      `json:"quantity"`
+	}
+
+	values := NewValues()
+	cart := Cart{Items: []string{"a", "b"}, Quantity: 2}
+
+	if err := values.SetJSON("cart", cart); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if expected, result := `{"items":["a","b"],"quantity":2}`, values.Get("cart"); result != expected {
+		t.Errorf("Expected %q, got %q.", expected, result)
+	}
+
+	var result Cart
+	if err := values.GetJSON("cart", &result); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if !reflect.DeepEqual(result, cart) {
+		t.Errorf("Expected %v, got %v", cart, result)
+	}
+
+	missing := Cart{Quantity: 1}
+	if err := values.GetJSON("missing", &missing); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if missing.Quantity != 1 {
+		t.Errorf("Expected dest to be unchanged, got %v", missing)
+	}
+
+	if err := values.SetJSON("func", func() {}); err == nil {
+		t.Errorf("Expected error for unsupported value.")
+	}
+}
+
 // func TestValuesFromJSON(t *testing.T) {
 // 	data := []byte("[{\"message\":\"messageA\",\"type\":\"typeA\"},{\"message\":\"messageB\",\"type\":\"typeB\"}]")
 // 	expected := []Flash{
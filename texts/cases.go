@@ -0,0 +1,121 @@
+package texts
+
+import (
+	"strings"
+	"unicode"
+)
+
+// acronyms are words that ToCamelCase and ToPascalCase write in upper case,
+// e.g. “user_id” becomes “UserID” instead of “UserId”.
+var acronyms = map[string]bool{
+	"ACL":   true,
+	"API":   true,
+	"ASCII": true,
+	"CPU":   true,
+	"CSS":   true,
+	"DNS":   true,
+	"EOF":   true,
+	"GUID":  true,
+	"HTML":  true,
+	"HTTP":  true,
+	"HTTPS": true,
+	"ID":    true,
+	"IP":    true,
+	"JSON":  true,
+	"RAM":   true,
+	"RPC":   true,
+	"SQL":   true,
+	"SSH":   true,
+	"TCP":   true,
+	"TLS":   true,
+	"TTL":   true,
+	"UDP":   true,
+	"UI":    true,
+	"URI":   true,
+	"URL":   true,
+	"UTF8":  true,
+	"UUID":  true,
+	"XML":   true,
+}
+
+// ToCamelCase converts text to camel case, e.g. “user_id” to “userID”.
+func ToCamelCase(text string) string {
+	words := splitWords(text)
+	for i, word := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(word)
+		} else {
+			words[i] = capitalize(word)
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// ToKebabCase converts text to kebab case, e.g. “UserID” to “user-id”.
+func ToKebabCase(text string) string {
+	return strings.ToLower(strings.Join(splitWords(text), "-"))
+}
+
+// ToPascalCase converts text to Pascal case, e.g. “user_id” to “UserID”.
+func ToPascalCase(text string) string {
+	words := splitWords(text)
+	for i, word := range words {
+		words[i] = capitalize(word)
+	}
+	return strings.Join(words, "")
+}
+
+// ToSnakeCase converts text to snake case, e.g. “UserID” to “user_id”.
+func ToSnakeCase(text string) string {
+	return strings.ToLower(strings.Join(splitWords(text), "_"))
+}
+
+// capitalize returns word in upper case if it is an acronym. Otherwise, it
+// returns word with its first letter in upper case and the remaining letters
+// in lower case.
+func capitalize(word string) string {
+	if upper := strings.ToUpper(word); acronyms[upper] {
+		return upper
+	}
+
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// splitWords splits text into words. Words are separated by characters that
+// are neither letters nor digits, and by changes from lower to upper case. A
+// run of upper case letters is a word of its own, except for its last letter
+// if that is followed by a lower case letter, e.g. “HTTPServer” is split into
+// “HTTP” and “Server”. Digits belong to the word they follow.
+func splitWords(text string) []string {
+	var words []string
+	var word []rune
+
+	runes := []rune(text)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		}
+
+		if len(word) > 0 && unicode.IsUpper(r) {
+			previous := word[len(word)-1]
+			isNextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			if !unicode.IsUpper(previous) || isNextLower {
+				words = append(words, string(word))
+				word = nil
+			}
+		}
+		word = append(word, r)
+	}
+
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
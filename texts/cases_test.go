@@ -0,0 +1,45 @@
+package texts
+
+import "testing"
+
+func TestCaseConversion(t *testing.T) {
+	type Test struct {
+		text   string
+		camel  string
+		kebab  string
+		pascal string
+		snake  string
+	}
+
+	tests := []*Test{
+		{"", "", "", "", ""},
+		{"user", "user", "user", "User", "user"},
+		{"UserID", "userID", "user-id", "UserID", "user_id"},
+		{"user_id", "userID", "user-id", "UserID", "user_id"},
+		{"user-id", "userID", "user-id", "UserID", "user_id"},
+		{"userId", "userID", "user-id", "UserID", "user_id"},
+		{"HTTPServer", "httpServer", "http-server", "HTTPServer", "http_server"},
+		{"parseHTMLAndJSON", "parseHTMLAndJSON", "parse-html-and-json", "ParseHTMLAndJSON", "parse_html_and_json"},
+		{"ABC", "abc", "abc", "Abc", "abc"},
+		{"  hello   world  ", "helloWorld", "hello-world", "HelloWorld", "hello_world"},
+		{"2fa_code", "2faCode", "2fa-code", "2faCode", "2fa_code"},
+		{"version2Name", "version2Name", "version2-name", "Version2Name", "version2_name"},
+		{"Größe_ändern", "größeÄndern", "größe-ändern", "GrößeÄndern", "größe_ändern"},
+		{"ÜberStraße", "überStraße", "über-straße", "ÜberStraße", "über_straße"},
+	}
+
+	for _, test := range tests {
+		if result := ToCamelCase(test.text); result != test.camel {
+			t.Errorf("ToCamelCase(%q) returned %q, expected %q", test.text, result, test.camel)
+		}
+		if result := ToKebabCase(test.text); result != test.kebab {
+			t.Errorf("ToKebabCase(%q) returned %q, expected %q", test.text, result, test.kebab)
+		}
+		if result := ToPascalCase(test.text); result != test.pascal {
+			t.Errorf("ToPascalCase(%q) returned %q, expected %q", test.text, result, test.pascal)
+		}
+		if result := ToSnakeCase(test.text); result != test.snake {
+			t.Errorf("ToSnakeCase(%q) returned %q, expected %q", test.text, result, test.snake)
+		}
+	}
+}
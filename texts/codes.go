@@ -0,0 +1,38 @@
+package texts
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+// CodeAlphabet is the alphabet Code uses if none is provided. It lacks
+// characters that are easily confused, e.g. “0” and “O” or “1”, “I” and “l”.
+const CodeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// Code returns a random code of length characters drawn from alphabet, e.g.
+// for coupons or short links. If alphabet is empty, CodeAlphabet is used. The
+// code is generated with crypto/rand, and every character of alphabet is
+// equally likely at each position.
+func Code(length int, alphabet string) (string, error) {
+	if length < 0 {
+		return "", errors.New("texts.Code: length must not be negative")
+	}
+	if alphabet == "" {
+		alphabet = CodeAlphabet
+	}
+
+	characters := []rune(alphabet)
+	max := big.NewInt(int64(len(characters)))
+	code := make([]rune, length)
+
+	for i := range code {
+		// rand.Int draws uniformly from [0, max), so there is no modulo bias.
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = characters[n.Int64()]
+	}
+	return string(code), nil
+}
@@ -0,0 +1,51 @@
+package texts
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		length   int
+		alphabet string
+	}{
+		{0, ""},
+		{8, ""},
+		{100, ""},
+		{100, "ab"},
+		{50, "äöü€"},
+	}
+
+	for _, test := range tests {
+		code, err := Code(test.length, test.alphabet)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		alphabet := test.alphabet
+		if alphabet == "" {
+			alphabet = CodeAlphabet
+		}
+
+		if length := utf8.RuneCountInString(code); length != test.length {
+			t.Errorf("Expected length %d, got %d (%q).", test.length, length, code)
+		}
+		for _, character := range code {
+			if !strings.ContainsRune(alphabet, character) {
+				t.Errorf("Character %q of %q is not in alphabet %q.", character, code, alphabet)
+			}
+		}
+	}
+
+	if _, err := Code(-1, ""); err == nil {
+		t.Error("Expected error for negative length.")
+	}
+}
+
+func TestCodeAlphabet(t *testing.T) {
+	if strings.ContainsAny(CodeAlphabet, "0O1Il") {
+		t.Errorf("CodeAlphabet contains ambiguous characters: %q", CodeAlphabet)
+	}
+}
@@ -13,3 +13,11 @@ func ExampleTruncate() {
 	// Hello …
 	// Hello wor…
 }
+
+func ExampleWrap() {
+	fmt.Println(texts.Wrap("The quick brown fox jumps over the lazy dog", 16))
+	// Output:
+	// The quick brown
+	// fox jumps over
+	// the lazy dog
+}
@@ -0,0 +1,20 @@
+package texts
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// fingerprintLength is the number of bytes of the SHA-256 hash that
+// Fingerprint keeps.
+const fingerprintLength = 16
+
+// Fingerprint returns a short, stable fingerprint of text, e.g. for cache keys
+// or for detecting duplicates. It is the base64url encoding without padding of
+// the first 128 bits of text’s SHA-256 hash, so it is 22 characters long and
+// safe to use in URLs and file names. Equal texts have equal fingerprints.
+// Fingerprints are not secret and must not be used as password hashes.
+func Fingerprint(text string) string {
+	hash := sha256.Sum256([]byte(text))
+	return base64.RawURLEncoding.EncodeToString(hash[:fingerprintLength])
+}
@@ -0,0 +1,31 @@
+package texts
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"", "47DEQpj8HBSa-_TImW-5JA"},
+		{"hello", "LPJNul-wow4m6Dsqxbning"},
+	}
+
+	for _, test := range tests {
+		if result := Fingerprint(test.text); result != test.expected {
+			t.Errorf("Expected %q for %q, got %q", test.expected, test.text, result)
+		}
+	}
+
+	urlSafe := regexp.MustCompile(`^[A-Za-z0-9_-]{22}$`)
+	for _, text := range []string{"a", "äöü€", "Hello, world!"} {
+		if result := Fingerprint(text); !urlSafe.MatchString(result) {
+			t.Errorf("Expected URL-safe fingerprint of 22 characters for %q, got %q", text, result)
+		} else if result == Fingerprint(text+" ") {
+			t.Errorf("Expected different fingerprints for %q and %q", text, text+" ")
+		}
+	}
+}
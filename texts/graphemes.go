@@ -0,0 +1,92 @@
+package texts
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const zeroWidthJoiner = '‍'
+
+// CountGraphemes returns the number of graphemes in text. A grapheme is what
+// users perceive as a single character, e.g. “é” written as “e” followed by a
+// combining accent, or a flag emoji made of two regional indicators.
+func CountGraphemes(text string) int {
+	count := 0
+	for len(text) > 0 {
+		text = text[graphemeLength(text):]
+		count++
+	}
+	return count
+}
+
+// TruncateGraphemes shortens text until the number of graphemes of the
+// shortened text and appended suffix is equal to or less than maxGraphemes.
+// Unlike Truncate, it never cuts a grapheme in half, see CountGraphemes. text
+// is cut mid-word.
+func TruncateGraphemes(text string, maxGraphemes int, suffix string) string {
+	if CountGraphemes(text) <= maxGraphemes {
+		return text
+	}
+
+	suffixLength := CountGraphemes(suffix)
+
+	if suffixLength > maxGraphemes {
+		return ""
+	}
+
+	end := 0
+	for i := 0; i < maxGraphemes-suffixLength; i++ {
+		end += graphemeLength(text[end:])
+	}
+	return text[:end] + suffix
+}
+
+// graphemeLength returns the length in bytes of the grapheme at the beginning
+// of text. It covers the common cases of Unicode’s extended grapheme clusters:
+// CR LF, combining marks, variation selectors, emoji modifiers, emoji tag
+// sequences, zero width joiner sequences and regional indicator pairs.
+func graphemeLength(text string) int {
+	r, length := utf8.DecodeRuneInString(text)
+	if r == '\r' && strings.HasPrefix(text[length:], "\n") {
+		return length + 1
+	}
+
+	if isRegionalIndicator(r) {
+		if next, nextLength := utf8.DecodeRuneInString(text[length:]); isRegionalIndicator(next) {
+			length += nextLength
+		}
+	}
+
+	for length < len(text) {
+		next, nextLength := utf8.DecodeRuneInString(text[length:])
+
+		if next == zeroWidthJoiner {
+			length += nextLength
+			if length < len(text) {
+				_, joinedLength := utf8.DecodeRuneInString(text[length:])
+				length += joinedLength
+			}
+			continue
+		}
+
+		if !isExtending(next) {
+			break
+		}
+		length += nextLength
+	}
+	return length
+}
+
+// isExtending returns whether r extends the preceding grapheme.
+func isExtending(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		r >= 0x1f3fb && r <= 0x1f3ff || // Emoji modifiers (skin tones)
+		r >= 0xe0020 && r <= 0xe007f // Tags, e.g. in subdivision flags
+}
+
+// isRegionalIndicator returns whether r is a regional indicator symbol, two of
+// which form a flag.
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
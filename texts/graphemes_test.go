@@ -0,0 +1,51 @@
+package texts
+
+import "testing"
+
+func TestCountGraphemes(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"e\u0301", 1},         // e with combining acute accent
+		{"🇩🇪🇫🇷", 2},            // flags
+		{"👍🏽", 1},              // emoji with skin tone modifier
+		{"👩\u200d👩\u200d👧", 1}, // family, joined by zero width joiners
+		{"❤\ufe0f", 1},         // emoji with variation selector
+		{"🏴\U000E0067\U000E0062\U000E0065\U000E006E\U000E0067\U000E007F", 1}, // subdivision flag
+		{"a\r\nb", 3},
+	}
+
+	for i, test := range tests {
+		if result := CountGraphemes(test.text); result != test.expected {
+			t.Errorf("%d. Expected %d graphemes in %q, got %d.", i, test.expected, test.text, result)
+		}
+	}
+}
+
+func TestTruncateGraphemes(t *testing.T) {
+	tests := []struct {
+		text         string
+		maxGraphemes int
+		suffix       string
+		expected     string
+	}{
+		{"Lorem ipsum", -1, "…", ""},
+		{"Lorem ipsum", 0, "…", ""},
+		{"Lorem ipsum", 1, "…", "…"},
+		{"Lorem ipsum", 6, "…", "Lorem…"},
+		{"Lorem ipsum", 11, "…", "Lorem ipsum"},
+		{"Cafe\u0301 au lait", 5, "…", "Cafe\u0301…"},
+		{"Hello 🇩🇪🇫🇷!", 8, "…", "Hello 🇩🇪…"},
+		{"👩\u200d👩\u200d👧👍🏽", 2, "", "👩\u200d👩\u200d👧👍🏽"},
+		{"👩\u200d👩\u200d👧👍🏽!", 2, "", "👩\u200d👩\u200d👧👍🏽"},
+	}
+
+	for i, test := range tests {
+		if result := TruncateGraphemes(test.text, test.maxGraphemes, test.suffix); result != test.expected {
+			t.Errorf("%d. Expected %q, got %q.", i, test.expected, result)
+		}
+	}
+}
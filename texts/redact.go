@@ -0,0 +1,107 @@
+package texts
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RedactOptions determines which kinds of personal data Redact replaces.
+type RedactOptions struct {
+	// CardNumbers enables redacting payment card numbers: 13 to 19 digits,
+	// optionally grouped by spaces or hyphens, that pass the Luhn check.
+	CardNumbers bool
+
+	// EmailAddresses enables redacting e-mail addresses.
+	EmailAddresses bool
+
+	// PhoneNumbers enables redacting phone numbers: 7 to 15 digits,
+	// optionally separated by spaces, hyphens, dots, slashes or parentheses,
+	// that start with “+”, “(” or “0”. Numbers without such a prefix, e.g.
+	// “555-123-4567”, are not redacted, to avoid redacting dates, IDs and
+	// amounts.
+	PhoneNumbers bool
+
+	// Placeholder replaces redacted data.
+	Placeholder string
+}
+
+// DefaultRedactOptions are the options used by Redact.
+var DefaultRedactOptions = RedactOptions{
+	CardNumbers:    true,
+	EmailAddresses: true,
+	PhoneNumbers:   true,
+	Placeholder:    "[redacted]",
+}
+
+var (
+	cardNumberRegExp   = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	emailAddressRegExp = regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9-]+(?:\.[a-zA-Z0-9-]+)*\.[a-zA-Z]{2,}`)
+	phoneNumberRegExp  = regexp.MustCompile(`[+(]?\b\d[\d ()./-]{5,}\d\b`)
+)
+
+// Redact replaces card numbers, e-mail addresses and phone numbers in text
+// with a placeholder, e.g. before text is logged. See DefaultRedactOptions.
+func Redact(text string) string {
+	return DefaultRedactOptions.Redact(text)
+}
+
+// Redact replaces the kinds of personal data enabled in o with o.Placeholder.
+// Detection is conservative: it covers common formats, so text should not be
+// assumed to be free of personal data afterwards.
+func (o RedactOptions) Redact(text string) string {
+	if o.CardNumbers {
+		text = cardNumberRegExp.ReplaceAllStringFunc(text, func(match string) string {
+			if luhn(digits(match)) {
+				return o.Placeholder
+			}
+			return match
+		})
+	}
+
+	if o.EmailAddresses {
+		text = emailAddressRegExp.ReplaceAllString(text, o.Placeholder)
+	}
+
+	if o.PhoneNumbers {
+		text = phoneNumberRegExp.ReplaceAllStringFunc(text, func(match string) string {
+			count := len(digits(match))
+			if count < 7 || count > 15 || !strings.ContainsAny(match[:1], "+(0") {
+				return match
+			}
+			return o.Placeholder
+		})
+	}
+
+	return text
+}
+
+// digits returns the digits in s.
+func digits(s string) string {
+	var builder strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// luhn returns whether number, a string of digits, passes the Luhn check used
+// by payment card numbers.
+func luhn(number string) bool {
+	sum := 0
+	double := false
+
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
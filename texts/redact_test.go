@@ -0,0 +1,39 @@
+package texts
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"no personal data", "no personal data"},
+		{"Mail jane.doe+news@example.co.uk now.", "Mail [redacted] now."},
+		{"Card 4111 1111 1111 1111, thanks", "Card [redacted], thanks"},
+		{"Card 4111-1111-1111-1111", "Card [redacted]"},
+		// Fails the Luhn check
+		{"Order 4111111111111112", "Order 4111111111111112"},
+		{"Call +49 30 1234567.", "Call [redacted]."},
+		{"Call (030) 123 45 67 today", "Call [redacted] today"},
+		{"Call 030/1234567", "Call [redacted]"},
+		// Not redacted to avoid false positives
+		{"Due 2024-01-15, 1,234.56 EUR", "Due 2024-01-15, 1,234.56 EUR"},
+		{"Order 555-123-4567", "Order 555-123-4567"},
+		{"Version 1.2.3", "Version 1.2.3"},
+	}
+
+	for i, test := range tests {
+		if result := Redact(test.text); result != test.expected {
+			t.Errorf("%d. Expected %q, got %q", i, test.expected, result)
+		}
+	}
+}
+
+func TestRedactOptions_Redact(t *testing.T) {
+	options := RedactOptions{EmailAddresses: true, Placeholder: "***"}
+	text := "jane@example.com, +49 30 1234567"
+
+	if expected, result := "***, +49 30 1234567", options.Redact(text); result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
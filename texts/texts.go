@@ -1,7 +1,9 @@
-// Package texts provides string truncation.
+// Package texts provides string truncation, wrapping, case conversion, redaction,
+// fingerprinting and random code generation.
 package texts
 
 import (
+	"strings"
 	"unicode"
 	"unicode/utf8"
 )
@@ -48,3 +50,42 @@ func Truncate(text string, maxLength int, suffix string, exact bool) string {
 	truncatedText = append(truncatedText, []rune(suffix)...)
 	return string(truncatedText)
 }
+
+// Wrap inserts line breaks between words of text so no line is longer than
+// width runes. Words that are longer than width are put on a line of their own.
+// Existing line breaks are kept. Within a line, words are separated by a single
+// space, and whitespace at the beginning and end of a line is removed. If width
+// is less than or equal to 0, text is returned unchanged.
+func Wrap(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	wrapped := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			wrapped = append(wrapped, "")
+			continue
+		}
+
+		current := words[0]
+		currentLength := utf8.RuneCountInString(current)
+
+		for _, word := range words[1:] {
+			wordLength := utf8.RuneCountInString(word)
+			if currentLength+1+wordLength > width {
+				wrapped = append(wrapped, current)
+				current, currentLength = word, wordLength
+				continue
+			}
+			current += " " + word
+			currentLength += 1 + wordLength
+		}
+		wrapped = append(wrapped, current)
+	}
+
+	return strings.Join(wrapped, "\n")
+}
@@ -513,3 +513,26 @@ func TestTruncate(t *testing.T) {
 		}
 	}
 }
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		text     string
+		width    int
+		expected string
+	}{
+		{"Lorem ipsum dolor", 0, "Lorem ipsum dolor"},
+		{"Lorem ipsum dolor", -1, "Lorem ipsum dolor"},
+		{"", 10, ""},
+		{"Lorem ipsum dolor sit amet", 11, "Lorem ipsum\ndolor sit\namet"},
+		{"Lorem ipsum dolor sit amet", 5, "Lorem\nipsum\ndolor\nsit\namet"},
+		{"Supercalifragilistic is long", 10, "Supercalifragilistic\nis long"},
+		{"Lorem  ipsum   \n\ndolor sit", 9, "Lorem\nipsum\n\ndolor sit"},
+		{"Größe ändern über Straße", 12, "Größe ändern\nüber Straße"},
+	}
+
+	for _, test := range tests {
+		if result := Wrap(test.text, test.width); result != test.expected {
+			t.Errorf("Wrap(%q, %d) returned %q, expected %q.", test.text, test.width, result, test.expected)
+		}
+	}
+}
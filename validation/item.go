@@ -1,9 +1,16 @@
 package validation
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 	"unicode/utf8"
 )
@@ -16,29 +23,218 @@ const (
 	RuleTypeMaxLength
 	RuleTypeMinLength
 	RuleTypeRequired
+	RuleTypePhone
+	RuleTypeAccepted
+	RuleTypeMaxItems
+	RuleTypeMinItems
+	RuleTypeHexColor
+	RuleTypeLengthBetween
+	RuleTypeBetween
 )
 
-// Regular expression for validating an e-mail address.
-var eMailAddressRegExp = regexp.MustCompile("^[^@]+@[^@]+$")
+// EmailAddressRegExp is the regular expression used by EmailAddress. It only
+// requires a single “@” that is surrounded by other characters. It can be
+// replaced to change how EmailAddress validates.
+var EmailAddressRegExp = regexp.MustCompile("^[^@]+@[^@]+$")
+
+// StrictEmailAddressRegExp is the regular expression used by
+// StrictEmailAddress. It only allows the characters of RFC 5322’s dot-atom
+// form in the local part, and requires a domain name with at least two labels
+// and a top-level domain of at least two letters. It can be replaced to change
+// how StrictEmailAddress validates.
+var StrictEmailAddressRegExp = regexp.MustCompile("^[a-zA-Z0-9!#$%&'*+/=?^_`{|}~-]+(\\.[a-zA-Z0-9!#$%&'*+/=?^_`{|}~-]+)*@([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\\.)+[a-zA-Z]{2,63}$")
+
+// Now returns the current time. Past and Future compare values with it. It can
+// be replaced, e.g. in tests.
+var Now = time.Now
+
+// timeLayouts are the layouts of strings accepted by Past and Future: RFC 3339
+// and the formats of HTML date and datetime-local inputs.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// Regular expressions for validating hex color codes without and with alpha
+// channel.
+var (
+	hexColorRegExp      = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+	hexColorAlphaRegExp = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+)
+
+// Regular expression for validating a phone number.
+var phoneRegExp = regexp.MustCompile(`^\+?[0-9 ()\-]+$`)
+
+// Minimum and maximum number of digits in a phone number.
+const (
+	phoneMinDigits = 3
+	phoneMaxDigits = 15
+)
 
 // Item can have zero or more validation rules that are used to validate the
 // item’s value.
 type Item struct {
 	Rules []*Rule
+
+	// items contains the item and its siblings, name is the item’s name in
+	// items. Both are set by Items.Add.
+	items Items
+	name  string
+
+	// order is the position of the item among all items added by Items.Add.
+	order uint64
+
 	value interface{}
 }
 
+// Check validates value without creating Items. build adds the rules to a
+// temporary item, e.g. func(item *Item) { item.EmailAddress("invalid") }.
+// Rules that depend on sibling items, like Confirmed, cannot be used.
+func Check(value interface{}, build func(*Item)) (bool, string, error) {
+	item := &Item{value: value}
+	build(item)
+	return item.Validate()
+}
+
+// Accepted checks if the item’s value is true or a string that means true:
+// “1”, “true”, “yes” or “on”, ignoring case. This is useful for checkboxes
+// that must be checked, e.g. for accepting terms of service.
+func (i *Item) Accepted(message string) *Item {
+	i.Rules = append(i.Rules, &Rule{
+		Func: func(value interface{}) (bool, error) {
+			switch value := value.(type) {
+			case bool:
+				return value, nil
+			case string:
+				switch strings.ToLower(value) {
+				case "1", "on", "true", "yes":
+					return true, nil
+				}
+				return false, nil
+			}
+			return false, fmt.Errorf("validation.Item.Accepted: unsupported value type %T", value)
+		},
+		Message: message,
+		Type:    RuleTypeAccepted,
+	})
+	return i
+}
+
+// AllowedContentTypes checks if the item’s value, an uploaded file of type
+// *multipart.FileHeader, has one of the content types, e.g. “image/png”. A
+// type may end in “/*” to allow all subtypes, e.g. “image/*”. The content
+// type is detected from the file’s first 512 bytes with
+// http.DetectContentType, not taken from the client’s Content-Type header. A
+// nil value is valid, so optional uploads can be validated. Use Required to
+// require a file.
+func (i *Item) AllowedContentTypes(contentTypes []string, message string) *Item {
+	i.Rules = append(i.Rules, &Rule{
+		Func: func(value interface{}) (bool, error) {
+			if value == nil {
+				return true, nil
+			}
+
+			switch value := value.(type) {
+			case *multipart.FileHeader:
+				if value == nil {
+					return true, nil
+				}
+				contentType, err := detectContentType(value)
+				if err != nil {
+					return false, err
+				}
+				for _, allowed := range contentTypes {
+					if contentType == allowed || strings.HasSuffix(allowed, "/*") && strings.HasPrefix(contentType, allowed[:len(allowed)-1]) {
+						return true, nil
+					}
+				}
+				return false, nil
+			}
+			return false, fmt.Errorf("validation.Item.AllowedContentTypes: unsupported value type %T", value)
+		},
+		Args:    []interface{}{contentTypes},
+		Message: message,
+	})
+	return i
+}
+
+// Between checks if the item’s value is a number of at least min and at most
+// max. It is like Min and Max combined, but has a single message. If the value
+// is not a number, an error is returned, so use Number first to inform the
+// user about non-numeric input.
+func (i *Item) Between(min, max float64, message string) *Item {
+	i.Rules = append(i.Rules, &Rule{
+		Func: func(value interface{}) (bool, error) {
+			switch v := value.(type) {
+			case string:
+				number, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					return false, fmt.Errorf("validation.Item.Between: value is not a number: %w", err)
+				}
+				return number >= min && number <= max, nil
+			}
+			return false, fmt.Errorf("validation.Item.Between: unsupported value type %T", value)
+		},
+		Args:    []interface{}{min, max},
+		Coerce:  coerceFloat,
+		Message: message,
+		Type:    RuleTypeBetween,
+	})
+	return i
+}
+
+// Coerced returns the item’s value converted by the last rule that coerces
+// values, e.g. Number converts a numeric string to float64. If no rule coerces
+// values, the value is returned as is. Coerced should only be called after the
+// item was found to be valid.
+func (i *Item) Coerced() (interface{}, error) {
+	for j := len(i.Rules) - 1; j >= 0; j-- {
+		if coerce := i.Rules[j].Coerce; coerce != nil {
+			return coerce(i.value)
+		}
+	}
+	return i.value, nil
+}
+
+// Confirmed checks if the item’s value equals the value of its sibling item
+// whose name is the item’s name followed by “_confirmation”, e.g.
+// “password_confirmation” for “password”. The item must have been added with
+// Items.Add.
+func (i *Item) Confirmed(message string) *Item {
+	i.Rules = append(i.Rules, &Rule{
+		Func: func(value interface{}) (bool, error) {
+			confirmation, ok := i.items[i.name+"_confirmation"]
+			if !ok {
+				return false, fmt.Errorf("validation.Item.Confirmed: item %q not found", i.name+"_confirmation")
+			}
+			return reflect.DeepEqual(value, confirmation.value), nil
+		},
+		Message: message,
+	})
+	return i
+}
+
 // EmailAddress checks if the item’s value is an e-mail address. It only checks
-// the length and whether there is exactly one “at” sign preceded and followed
-// by at least one character.
+// the length and whether the value matches EmailAddressRegExp, which by
+// default means there is exactly one “at” sign preceded and followed by at
+// least one character. See StrictEmailAddress for a stricter check.
 func (i *Item) EmailAddress(message string) *Item {
+	return i.emailAddress("EmailAddress", func() *regexp.Regexp { return EmailAddressRegExp }, message)
+}
+
+// emailAddress adds a rule that checks if the item’s value has at most 254
+// characters and matches the regular expression returned by regExp. name is
+// the name of the calling method, used in error messages.
+func (i *Item) emailAddress(name string, regExp func() *regexp.Regexp, message string) *Item {
 	i.Rules = append(i.Rules, &Rule{
 		Func: func(value interface{}) (bool, error) {
 			switch value := value.(type) {
 			case string:
-				return utf8.RuneCountInString(value) <= 254 && eMailAddressRegExp.MatchString(value), nil
+				return utf8.RuneCountInString(value) <= 254 && regExp().MatchString(value), nil
 			}
-			return false, fmt.Errorf("validation.Item.EmailAddress: unsupported value type %T", value)
+			return false, fmt.Errorf("validation.Item.%s: unsupported value type %T", name, value)
 		},
 		Message: message,
 		Type:    RuleTypeEmailAddress,
@@ -66,6 +262,7 @@ func (i *Item) Equals(value2 interface{}, message string) *Item {
 	return i
 }
 
+// Func checks the item’s value with fn.
 func (i *Item) Func(fn func(value interface{}) (bool, error), message string) *Item {
 	i.Rules = append(i.Rules, &Rule{
 		Func:    fn,
@@ -74,6 +271,123 @@ func (i *Item) Func(fn func(value interface{}) (bool, error), message string) *I
 	return i
 }
 
+// FuncCtx checks the item’s value with fn, which receives the context passed
+// to ValidateCtx. This is useful for rules that query a database, e.g. to
+// check whether an e-mail address is already taken, and should stop when the
+// request is cancelled.
+func (i *Item) FuncCtx(fn func(ctx context.Context, value interface{}) (bool, error), message string) *Item {
+	i.Rules = append(i.Rules, &Rule{
+		FuncCtx: fn,
+		Message: message,
+	})
+	return i
+}
+
+// Future checks if the item’s value is a time after Now. The value can be a
+// time.Time or a string in one of the formats of RFC 3339, HTML date inputs
+// (“2006-01-02”) and HTML datetime-local inputs (“2006-01-02T15:04”). Strings
+// without time zone are interpreted as UTC. Strings in other formats are
+// invalid.
+func (i *Item) Future(message string) *Item {
+	i.Rules = append(i.Rules, &Rule{
+		Func: func(value interface{}) (bool, error) {
+			t, ok, err := timeValue("Future", value)
+			return ok && t.After(Now()), err
+		},
+		Message: message,
+	})
+	return i
+}
+
+// HexColor checks if the item’s value is a hex color code, i.e. “#RGB” or
+// “#RRGGBB”. If allowAlpha is true, “#RRGGBBAA” is valid, too. Letters can be
+// lowercase or uppercase.
+func (i *Item) HexColor(allowAlpha bool, message string) *Item {
+	regExp := hexColorRegExp
+	if allowAlpha {
+		regExp = hexColorAlphaRegExp
+	}
+
+	i.Rules = append(i.Rules, &Rule{
+		Args: []interface{}{allowAlpha},
+		Func: func(value interface{}) (bool, error) {
+			switch value := value.(type) {
+			case string:
+				return regExp.MatchString(value), nil
+			}
+			return false, fmt.Errorf("validation.Item.HexColor: unsupported value type %T", value)
+		},
+		Message: message,
+		Type:    RuleTypeHexColor,
+	})
+	return i
+}
+
+// InFunc checks if the item’s value is in a set of allowed values that is only
+// known at runtime, e.g. country codes loaded from a database. contains is
+// called with the value and returns whether it is allowed, e.g. by looking it
+// up in a map.
+func (i *Item) InFunc(contains func(value string) bool, message string) *Item {
+	i.Rules = append(i.Rules, &Rule{
+		Func: func(value interface{}) (bool, error) {
+			switch value := value.(type) {
+			case string:
+				return contains(value), nil
+			}
+			return false, fmt.Errorf("validation.Item.InFunc: unsupported value type %T", value)
+		},
+		Message: message,
+	})
+	return i
+}
+
+// JSON checks if the item’s value is valid JSON. Values of type string and
+// []byte are supported. The coerced value is of type json.RawMessage.
+func (i *Item) JSON(message string) *Item {
+	i.Rules = append(i.Rules, &Rule{
+		Func: func(value interface{}) (bool, error) {
+			switch value := value.(type) {
+			case string:
+				return json.Valid([]byte(value)), nil
+			case []byte:
+				return json.Valid(value), nil
+			}
+			return false, fmt.Errorf("validation.Item.JSON: unsupported value type %T", value)
+		},
+		Coerce: func(value interface{}) (interface{}, error) {
+			switch value := value.(type) {
+			case string:
+				return json.RawMessage(value), nil
+			case []byte:
+				return json.RawMessage(value), nil
+			}
+			return nil, fmt.Errorf("validation.Item.JSON: unsupported value type %T", value)
+		},
+		Message: message,
+	})
+	return i
+}
+
+// LengthBetween checks if the item’s value has a length of at least minLength
+// and at most maxLength. It is like MinLength and MaxLength combined, but has a
+// single message.
+func (i *Item) LengthBetween(minLength, maxLength int, message string) *Item {
+	i.Rules = append(i.Rules, &Rule{
+		Func: func(value interface{}) (bool, error) {
+			switch value := value.(type) {
+			case string:
+				length := utf8.RuneCountInString(value)
+				return length >= minLength && length <= maxLength, nil
+			}
+			return false, fmt.Errorf("validation.Item.LengthBetween: unsupported value type %T", value)
+		},
+		Args:    []interface{}{minLength, maxLength},
+		Message: message,
+		Type:    RuleTypeLengthBetween,
+	})
+	return i
+}
+
 // Max checks if the item’s value is equal or less than max.
 func (i *Item) Max(max float64, message string) *Item {
 	i.Rules = append(i.Rules, &Rule{
@@ -89,7 +403,69 @@ func (i *Item) Max(max float64, message string) *Item {
 			return false, fmt.Errorf("validation.Item.Max: unsupported value type %T", value)
 		},
 		Args:    []interface{}{max},
+		Coerce:  coerceFloat,
+		Message: message,
+	})
+	return i
+}
+
+// MaxBytes checks if the item’s value is at most maxBytes bytes long. Unlike
+// MaxLength, which counts characters, it counts bytes, which is useful for
+// values stored in byte-limited columns. Values of type string and []byte are
+// supported.
+func (i *Item) MaxBytes(maxBytes int, message string) *Item {
+	i.Rules = append(i.Rules, &Rule{
+		Func: func(value interface{}) (bool, error) {
+			switch value := value.(type) {
+			case string:
+				return len(value) <= maxBytes, nil
+			case []byte:
+				return len(value) <= maxBytes, nil
+			}
+			return false, fmt.Errorf("validation.Item.MaxBytes: unsupported value type %T", value)
+		},
+		Args:    []interface{}{maxBytes},
+		Message: message,
+	})
+	return i
+}
+
+// MaxFileSize checks if the item’s value, an uploaded file of type
+// *multipart.FileHeader, has a size of at most maxSize bytes. A nil value is
+// valid, so optional uploads can be validated. Use Required to require a file.
+func (i *Item) MaxFileSize(maxSize int64, message string) *Item {
+	i.Rules = append(i.Rules, &Rule{
+		Func: func(value interface{}) (bool, error) {
+			if value == nil {
+				return true, nil
+			}
+
+			switch value := value.(type) {
+			case *multipart.FileHeader:
+				return value == nil || value.Size <= maxSize, nil
+			}
+			return false, fmt.Errorf("validation.Item.MaxFileSize: unsupported value type %T", value)
+		},
+		Args:    []interface{}{maxSize},
+		Message: message,
+	})
+	return i
+}
+
+// MaxItems checks if the item’s value, a slice or array, has at most maxItems
+// elements, e.g. the selected options of a multi-select field.
+func (i *Item) MaxItems(maxItems int, message string) *Item {
+	i.Rules = append(i.Rules, &Rule{
+		Func: func(value interface{}) (bool, error) {
+			length, err := itemCount("MaxItems", value)
+			if err != nil {
+				return false, err
+			}
+			return length <= maxItems, nil
+		},
+		Args:    []interface{}{maxItems},
 		Message: message,
+		Type:    RuleTypeMaxItems,
 	})
 	return i
 }
@@ -129,11 +505,30 @@ func (i *Item) Min(min float64, message string) *Item {
 			return false, fmt.Errorf("validation.Item.Min: unsupported value type %T", value)
 		},
 		Args:    []interface{}{min},
+		Coerce:  coerceFloat,
 		Message: message,
 	})
 	return i
 }
 
+// MinItems checks if the item’s value, a slice or array, has at least
+// minItems elements, e.g. the checked boxes of a checkbox group.
+func (i *Item) MinItems(minItems int, message string) *Item {
+	i.Rules = append(i.Rules, &Rule{
+		Func: func(value interface{}) (bool, error) {
+			length, err := itemCount("MinItems", value)
+			if err != nil {
+				return false, err
+			}
+			return length >= minItems, nil
+		},
+		Args:    []interface{}{minItems},
+		Message: message,
+		Type:    RuleTypeMinItems,
+	})
+	return i
+}
+
 // MinLength checks if the item’s value has a minimum length of minLength.
 func (i *Item) MinLength(minLength int, message string) *Item {
 	i.Rules = append(i.Rules, &Rule{
@@ -165,6 +560,20 @@ func (i *Item) Number(message string) *Item {
 			}
 			return false, fmt.Errorf("validation.Item.Max: unsupported value type %T", value)
 		},
+		Coerce:  coerceFloat,
+		Message: message,
+	})
+	return i
+}
+
+// Past checks if the item’s value is a time before Now, e.g. a birth date.
+// Values are handled like by Future.
+func (i *Item) Past(message string) *Item {
+	i.Rules = append(i.Rules, &Rule{
+		Func: func(value interface{}) (bool, error) {
+			t, ok, err := timeValue("Past", value)
+			return ok && t.Before(Now()), err
+		},
 		Message: message,
 	})
 	return i
@@ -185,10 +594,63 @@ func (i *Item) Pattern(pattern *regexp.Regexp, message string) *Item {
 	return i
 }
 
-// Required checks if the item’s value is non-zero.
+// PatternAny checks if the item’s value matches at least one of the regular
+// expressions. Patterns are tried in order, and the first match ends the check.
+func (i *Item) PatternAny(patterns []*regexp.Regexp, message string) *Item {
+	i.Rules = append(i.Rules, &Rule{
+		Func: func(value interface{}) (bool, error) {
+			switch value := value.(type) {
+			case string:
+				for _, pattern := range patterns {
+					if pattern.MatchString(value) {
+						return true, nil
+					}
+				}
+				return false, nil
+			}
+			return false, fmt.Errorf("validation.Item.PatternAny: unsupported value type %T", value)
+		},
+		Message: message,
+	})
+	return i
+}
+
+// Phone checks if the item’s value looks like a phone number. It may start
+// with a plus sign and otherwise contain digits, spaces, hyphens and
+// parentheses. The number must have between 3 and 15 digits.
+func (i *Item) Phone(message string) *Item {
+	i.Rules = append(i.Rules, &Rule{
+		Func: func(value interface{}) (bool, error) {
+			switch value := value.(type) {
+			case string:
+				if !phoneRegExp.MatchString(value) {
+					return false, nil
+				}
+
+				digits := 0
+				for _, character := range value {
+					if character >= '0' && character <= '9' {
+						digits++
+					}
+				}
+				return digits >= phoneMinDigits && digits <= phoneMaxDigits, nil
+			}
+			return false, fmt.Errorf("validation.Item.Phone: unsupported value type %T", value)
+		},
+		Message: message,
+		Type:    RuleTypePhone,
+	})
+	return i
+}
+
+// Required checks if the item’s value is non-zero. A nil value is invalid.
 func (i *Item) Required(message string) *Item {
 	i.Rules = append(i.Rules, &Rule{
 		Func: func(value interface{}) (bool, error) {
+			if value == nil {
+				return false, nil
+			}
+
 			switch value := value.(type) {
 			case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
 				return value != 0, nil
@@ -198,6 +660,8 @@ func (i *Item) Required(message string) *Item {
 				return !value.IsZero(), nil
 			case []string:
 				return len(value) > 0, nil
+			case *multipart.FileHeader:
+				return value != nil, nil
 			}
 			return false, fmt.Errorf("validation.Item.Required: unsupported value type %T", value)
 		},
@@ -208,20 +672,173 @@ func (i *Item) Required(message string) *Item {
 	return i
 }
 
+// StrictEmailAddress checks if the item’s value is an e-mail address like
+// EmailAddress, but uses StrictEmailAddressRegExp, which e.g. rejects
+// “a@b” and addresses containing whitespace.
+func (i *Item) StrictEmailAddress(message string) *Item {
+	return i.emailAddress("StrictEmailAddress", func() *regexp.Regexp { return StrictEmailAddressRegExp }, message)
+}
+
+// ValidUTF8 checks if the item’s value is valid UTF-8. Values of type string
+// and []byte are supported.
+func (i *Item) ValidUTF8(message string) *Item {
+	i.Rules = append(i.Rules, &Rule{
+		Func: func(value interface{}) (bool, error) {
+			switch value := value.(type) {
+			case string:
+				return utf8.ValidString(value), nil
+			case []byte:
+				return utf8.Valid(value), nil
+			}
+			return false, fmt.Errorf("validation.Item.ValidUTF8: unsupported value type %T", value)
+		},
+		Message: message,
+	})
+	return i
+}
+
 // Validate checks if the item’s value is valid according to the specified
 // validation rules. If it is valid, the function returns true. If it is not
 // valid, the rule’s validation error message is returned. If an error
 // occurred, the error is returned. A returned error does not mean the value is
 // invalid, it solely means something went wrong. Rules are checked in order of
 // creation. If the item’s value was found to be invalid, any further rules are
-// not checked.
+// not checked. Failing rules with SeverityWarning do not make the value
+// invalid, see ValidateWarnings.
 func (i *Item) Validate() (bool, string, error) {
+	return i.ValidateCtx(context.Background())
+}
+
+// ValidateCtx is like Validate, but passes ctx to rules created with FuncCtx.
+// If ctx is done before all rules were checked, ctx’s error is returned.
+func (i *Item) ValidateCtx(ctx context.Context) (bool, string, error) {
+	failed, _, err := i.validate(ctx)
+	if err != nil || failed != nil {
+		return false, ruleMessage(failed), err
+	}
+	return true, "", nil
+}
+
+// ValidateWarnings is like Validate, but additionally returns the message of
+// the first failing rule with SeverityWarning. If the value is invalid, the
+// warning is empty.
+func (i *Item) ValidateWarnings() (isValid bool, message, warning string, err error) {
+	failed, warned, err := i.validate(context.Background())
+	if err != nil || failed != nil {
+		return false, ruleMessage(failed), "", err
+	}
+	return true, "", ruleMessage(warned), nil
+}
+
+// validate returns the first failing rule with SeverityError, or if there is
+// none, the first failing rule with SeverityWarning.
+func (i *Item) validate(ctx context.Context) (failed, warned *Rule, err error) {
 	for _, rule := range i.Rules {
-		if isValid, err := rule.Func(i.value); err != nil {
-			return false, "", err
-		} else if !isValid {
-			return false, rule.Message, nil
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
 		}
+
+		var isValid bool
+		var err error
+
+		if rule.FuncCtx != nil {
+			isValid, err = rule.FuncCtx(ctx, i.value)
+		} else {
+			isValid, err = rule.Func(i.value)
+		}
+
+		if err != nil {
+			return nil, nil, err
+		} else if isValid {
+			continue
+		} else if rule.Severity == SeverityWarning {
+			if warned == nil {
+				warned = rule
+			}
+			continue
+		}
+		return rule, nil, nil
 	}
-	return true, "", nil
+	return nil, warned, nil
+}
+
+// ruleMessage returns rule’s message, or an empty string if rule is nil.
+func ruleMessage(rule *Rule) string {
+	if rule == nil {
+		return ""
+	}
+	return rule.Message
+}
+
+// Warn sets the severity of the item’s last rule to SeverityWarning, e.g.
+// item.MinLength(12, "weak password").Warn(). If the item has no rules,
+// nothing happens.
+func (i *Item) Warn() *Item {
+	if len(i.Rules) > 0 {
+		i.Rules[len(i.Rules)-1].Severity = SeverityWarning
+	}
+	return i
+}
+
+// timeValue converts value, a time.Time or a string in one of timeLayouts, to
+// time.Time. ok is false if value is a string in another format. name is the
+// name of the calling method, used in error messages.
+func timeValue(name string, value interface{}) (t time.Time, ok bool, err error) {
+	switch value := value.(type) {
+	case time.Time:
+		return value, true, nil
+	case string:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, true, nil
+			}
+		}
+		return time.Time{}, false, nil
+	}
+	return time.Time{}, false, fmt.Errorf("validation.Item.%s: unsupported value type %T", name, value)
+}
+
+// itemCount returns the number of elements of value, which must be a slice or
+// an array. name is the name of the calling method, used in error messages.
+func itemCount(name string, value interface{}) (int, error) {
+	if value, ok := value.([]string); ok {
+		return len(value), nil
+	}
+
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Array, reflect.Slice:
+		return v.Len(), nil
+	}
+	return 0, fmt.Errorf("validation.Item.%s: unsupported value type %T", name, value)
+}
+
+// coerceFloat converts a numeric string to float64.
+func coerceFloat(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+	return nil, fmt.Errorf("validation.coerceFloat: unsupported value type %T", value)
+}
+
+// detectContentType returns the media type of the uploaded file without
+// parameters, e.g. “text/plain” for “text/plain; charset=utf-8”.
+func detectContentType(fileHeader *multipart.FileHeader) (string, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	b := make([]byte, 512)
+	n, err := io.ReadFull(file, b)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	contentType := http.DetectContentType(b[:n])
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return contentType, nil
 }
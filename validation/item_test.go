@@ -0,0 +1,615 @@
+package validation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestItem_Accepted(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected bool
+		wantErr  bool
+	}{
+		{"1", true, false},
+		{"on", true, false},
+		{"true", true, false},
+		{"Yes", true, false},
+		{true, true, false},
+		{"", false, false},
+		{"0", false, false},
+		{"no", false, false},
+		{false, false, false},
+		{1, false, true},
+	}
+
+	for _, test := range tests {
+		item := &Item{value: test.value}
+		isValid, message, err := item.Accepted("must be accepted").Validate()
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("Accepted(%v): unexpected error %v", test.value, err)
+		} else if isValid != test.expected {
+			t.Errorf("Accepted(%v): Expected %t, got %t", test.value, test.expected, isValid)
+		} else if !isValid && !test.wantErr && message != "must be accepted" {
+			t.Errorf("Accepted(%v): Expected message %q, got %q", test.value, "must be accepted", message)
+		}
+	}
+}
+
+func TestItem_Between(t *testing.T) {
+	tests := []struct {
+		value     interface{}
+		expected  bool
+		expectErr bool
+	}{
+		{"1", true, false},
+		{"5.5", true, false},
+		{"10", true, false},
+		{"0.99", false, false},
+		{"10.01", false, false},
+		{"-3", false, false},
+		{"abc", false, true},
+		{1, false, true},
+	}
+
+	for i, test := range tests {
+		isValid, _, err := Check(test.value, func(item *Item) {
+			item.Between(1, 10, "between 1 and 10")
+		})
+		if (err != nil) != test.expectErr {
+			t.Errorf("%d. Expected error %t, got %v", i, test.expectErr, err)
+		} else if isValid != test.expected {
+			t.Errorf("%d. Expected %t for %v, got %t", i, test.expected, test.value, isValid)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		value           interface{}
+		expectedIsValid bool
+		expectedMessage string
+	}{
+		{"jane@example.com", true, ""},
+		{"", false, "required"},
+		{"jane", false, "invalid e-mail address"},
+	}
+
+	for i, test := range tests {
+		isValid, message, err := Check(test.value, func(item *Item) {
+			item.Required("required").EmailAddress("invalid e-mail address")
+		})
+
+		if err != nil {
+			t.Errorf("%d. Unexpected error: %s", i, err)
+		} else if isValid != test.expectedIsValid || message != test.expectedMessage {
+			t.Errorf("%d. Expected %t, %q, got %t, %q", i, test.expectedIsValid, test.expectedMessage, isValid, message)
+		}
+	}
+}
+
+func TestItem_Past_Future(t *testing.T) {
+	defer func() { Now = time.Now }()
+	Now = func() time.Time {
+		return time.Date(2020, 6, 15, 12, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		value          interface{}
+		expectedPast   bool
+		expectedFuture bool
+	}{
+		{time.Date(2020, 6, 15, 11, 0, 0, 0, time.UTC), true, false},
+		{time.Date(2020, 6, 15, 13, 0, 0, 0, time.UTC), false, true},
+		{time.Date(2020, 6, 15, 12, 0, 0, 0, time.UTC), false, false},
+		{"1990-01-31", true, false},
+		{"2020-06-16", false, true},
+		{"2020-06-15T11:30", true, false},
+		{"2020-06-15T12:30:00+02:00", true, false},
+		{"31.01.1990", false, false},
+		{"", false, false},
+	}
+
+	for i, test := range tests {
+		if isValid, _, err := Check(test.value, func(item *Item) { item.Past("not in past") }); err != nil {
+			t.Errorf("%d. Unexpected error: %s", i, err)
+		} else if isValid != test.expectedPast {
+			t.Errorf("%d. Expected Past %t for %v, got %t", i, test.expectedPast, test.value, isValid)
+		}
+
+		if isValid, _, err := Check(test.value, func(item *Item) { item.Future("not in future") }); err != nil {
+			t.Errorf("%d. Unexpected error: %s", i, err)
+		} else if isValid != test.expectedFuture {
+			t.Errorf("%d. Expected Future %t for %v, got %t", i, test.expectedFuture, test.value, isValid)
+		}
+	}
+
+	if _, _, err := Check(1, func(item *Item) { item.Past("not in past") }); err == nil {
+		t.Errorf("Expected error for unsupported value type.")
+	}
+}
+
+// fileHeaders returns the file headers of a parsed multipart form that
+// contains files, keyed by file name.
+func fileHeaders(t *testing.T, files map[string][]byte) map[string]*multipart.FileHeader {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, content := range files {
+		part, err := writer.CreateFormFile(name, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(content)
+	}
+	writer.Close()
+
+	request := httptest.NewRequest("POST", "/", body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	if err := request.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatal(err)
+	}
+
+	headers := make(map[string]*multipart.FileHeader)
+	for name, fileHeaders := range request.MultipartForm.File {
+		headers[name] = fileHeaders[0]
+	}
+	return headers
+}
+
+func TestItem_MaxFileSize_AllowedContentTypes(t *testing.T) {
+	files := fileHeaders(t, map[string][]byte{
+		"image.png": []byte("\x89PNG\r\n\x1a\n0000"),
+		"text.txt":  []byte("Hello, world!"),
+	})
+
+	tests := []struct {
+		value         interface{}
+		maxSize       int64
+		contentTypes  []string
+		expectedSize  bool
+		expectedTypes bool
+	}{
+		{files["image.png"], 12, []string{"image/png"}, true, true},
+		{files["image.png"], 11, []string{"image/*"}, false, true},
+		{files["image.png"], 12, []string{"text/plain"}, true, false},
+		{files["text.txt"], 13, []string{"image/*", "text/plain"}, true, true},
+		{files["text.txt"], 13, []string{"text/plain; charset=utf-8", "image/png"}, true, false},
+		{(*multipart.FileHeader)(nil), 0, nil, true, true},
+		{nil, 0, nil, true, true},
+	}
+
+	for i, test := range tests {
+		if isValid, _, err := Check(test.value, func(item *Item) { item.MaxFileSize(test.maxSize, "too large") }); err != nil {
+			t.Errorf("%d. Unexpected error: %s", i, err)
+		} else if isValid != test.expectedSize {
+			t.Errorf("%d. Expected MaxFileSize to return %t, got %t", i, test.expectedSize, isValid)
+		}
+
+		if isValid, _, err := Check(test.value, func(item *Item) { item.AllowedContentTypes(test.contentTypes, "wrong type") }); err != nil {
+			t.Errorf("%d. Unexpected error: %s", i, err)
+		} else if isValid != test.expectedTypes {
+			t.Errorf("%d. Expected AllowedContentTypes to return %t, got %t", i, test.expectedTypes, isValid)
+		}
+	}
+
+	var missing *multipart.FileHeader
+	if isValid, _, err := Check(missing, func(item *Item) { item.Required("required") }); err != nil || isValid {
+		t.Errorf("Expected missing file to be invalid, got %t, %v", isValid, err)
+	}
+	if _, _, err := Check("foo", func(item *Item) { item.MaxFileSize(1, "too large") }); err == nil {
+		t.Errorf("Expected error for unsupported value type.")
+	}
+}
+
+func TestItem_Required(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected bool
+	}{
+		{"foo", true},
+		{"", false},
+		{42, true},
+		{0, false},
+		{[]string{"foo"}, true},
+		{[]string{}, false},
+		{time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{time.Time{}, false},
+		{nil, false},
+	}
+
+	for i, test := range tests {
+		if isValid, _, err := Check(test.value, func(item *Item) { item.Required("required") }); err != nil {
+			t.Errorf("%d. Unexpected error: %s", i, err)
+		} else if isValid != test.expected {
+			t.Errorf("%d. Expected Required to return %t for %#v, got %t", i, test.expected, test.value, isValid)
+		}
+	}
+}
+
+func TestItem_HexColor(t *testing.T) {
+	tests := []struct {
+		value      interface{}
+		allowAlpha bool
+		expected   bool
+	}{
+		{"#fff", false, true},
+		{"#A0b1C2", false, true},
+		{"#a0b1c2ff", false, false},
+		{"#a0b1c2ff", true, true},
+		{"#ffff", true, false},
+		{"fff", false, false},
+		{"#ggg", false, false},
+		{"", false, false},
+	}
+
+	for i, test := range tests {
+		isValid, _, err := Check(test.value, func(item *Item) {
+			item.HexColor(test.allowAlpha, "invalid color")
+		})
+		if err != nil {
+			t.Errorf("%d. Unexpected error: %s", i, err)
+		} else if isValid != test.expected {
+			t.Errorf("%d. Expected %t for %q, got %t", i, test.expected, test.value, isValid)
+		}
+	}
+
+	if _, _, err := Check(1, func(item *Item) { item.HexColor(false, "invalid color") }); err == nil {
+		t.Errorf("Expected error for unsupported value type.")
+	}
+}
+
+func TestItem_LengthBetween(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected bool
+	}{
+		{"", false},
+		{"a", false},
+		{"ab", true},
+		{"äöü", true},
+		{"abcd", true},
+		{"abcde", false},
+	}
+
+	for i, test := range tests {
+		isValid, message, err := Check(test.value, func(item *Item) {
+			item.LengthBetween(2, 4, "2 to 4 characters")
+		})
+		if err != nil {
+			t.Errorf("%d. Unexpected error: %s", i, err)
+		} else if isValid != test.expected {
+			t.Errorf("%d. Expected %t for %q, got %t", i, test.expected, test.value, isValid)
+		} else if !isValid && message != "2 to 4 characters" {
+			t.Errorf("%d. Unexpected message %q", i, message)
+		}
+	}
+
+	if _, _, err := Check(1, func(item *Item) { item.LengthBetween(2, 4, "2 to 4 characters") }); err == nil {
+		t.Errorf("Expected error for unsupported value type.")
+	}
+}
+
+func TestItem_MaxBytes(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected bool
+	}{
+		{"", true},
+		{"abcd", true},
+		{"abcde", false},
+		{"äöü", false},
+		{"äö", true},
+		{[]byte("abcd"), true},
+		{[]byte("abcde"), false},
+	}
+
+	for i, test := range tests {
+		isValid, _, err := Check(test.value, func(item *Item) {
+			item.MaxBytes(4, "too long")
+		})
+		if err != nil {
+			t.Errorf("%d. Unexpected error: %s", i, err)
+		} else if isValid != test.expected {
+			t.Errorf("%d. Expected %t for %q, got %t", i, test.expected, test.value, isValid)
+		}
+	}
+
+	if _, _, err := Check(1, func(item *Item) { item.MaxBytes(4, "too long") }); err == nil {
+		t.Errorf("Expected error for unsupported value type.")
+	}
+}
+
+func TestItem_ValidUTF8(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected bool
+	}{
+		{"", true},
+		{"äöü", true},
+		{"a\xffb", false},
+		{[]byte("äöü"), true},
+		{[]byte{0xc3}, false},
+	}
+
+	for i, test := range tests {
+		isValid, _, err := Check(test.value, func(item *Item) {
+			item.ValidUTF8("invalid encoding")
+		})
+		if err != nil {
+			t.Errorf("%d. Unexpected error: %s", i, err)
+		} else if isValid != test.expected {
+			t.Errorf("%d. Expected %t for %q, got %t", i, test.expected, test.value, isValid)
+		}
+	}
+
+	if _, _, err := Check(1, func(item *Item) { item.ValidUTF8("invalid encoding") }); err == nil {
+		t.Errorf("Expected error for unsupported value type.")
+	}
+}
+
+func TestItem_JSON(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected bool
+		wantErr  bool
+	}{
+		{`{"a": [1, 2, null]}`, true, false},
+		{`"foo"`, true, false},
+		{"42", true, false},
+		{[]byte(`{"a": true}`), true, false},
+		{"", false, false},
+		{`{"a": }`, false, false},
+		{`{a: 1}`, false, false},
+		{[]byte("[1,"), false, false},
+		{42, false, true},
+	}
+
+	for _, test := range tests {
+		item := &Item{value: test.value}
+		isValid, _, err := item.JSON("invalid").Validate()
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("JSON(%q): unexpected error %v", test.value, err)
+		} else if isValid != test.expected {
+			t.Errorf("JSON(%q): Expected %t, got %t", test.value, test.expected, isValid)
+		}
+	}
+
+	item := &Item{value: `{"a":1}`}
+	if coerced, err := item.JSON("invalid").Coerced(); err != nil {
+		t.Errorf("Coerced: unexpected error %v", err)
+	} else if raw, ok := coerced.(json.RawMessage); !ok || string(raw) != `{"a":1}` {
+		t.Errorf("Coerced: Expected json.RawMessage, got %#v", coerced)
+	}
+}
+
+func TestItem_MinItems_MaxItems(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected bool
+		wantErr  bool
+	}{
+		{[]string{"a", "b"}, true, false},
+		{[]string{"a", "b", "c"}, true, false},
+		{[]int{1, 2, 3}, true, false},
+		{[2]string{"a", "b"}, true, false},
+		{[]string{"a"}, false, false},
+		{[]string(nil), false, false},
+		{[]string{"a", "b", "c", "d"}, false, false},
+		{"ab", false, true},
+	}
+
+	for _, test := range tests {
+		item := &Item{value: test.value}
+		isValid, message, err := item.MinItems(2, "too few").MaxItems(3, "too many").Validate()
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("%v: unexpected error %v", test.value, err)
+		} else if isValid != test.expected {
+			t.Errorf("%v: Expected %t, got %t (%s)", test.value, test.expected, isValid, message)
+		}
+	}
+}
+
+func TestItem_InFunc(t *testing.T) {
+	countryCodes := map[string]bool{"DE": true, "FR": true}
+	contains := func(value string) bool { return countryCodes[value] }
+
+	tests := []struct {
+		value    interface{}
+		expected bool
+	}{
+		{"DE", true},
+		{"FR", true},
+		{"de", false},
+		{"XX", false},
+		{"", false},
+	}
+
+	for i, test := range tests {
+		isValid, _, err := Check(test.value, func(item *Item) {
+			item.InFunc(contains, "unknown country")
+		})
+		if err != nil {
+			t.Errorf("%d. Unexpected error: %s", i, err)
+		} else if isValid != test.expected {
+			t.Errorf("%d. Expected %t for %q, got %t", i, test.expected, test.value, isValid)
+		}
+	}
+
+	if _, _, err := Check(1, func(item *Item) { item.InFunc(contains, "unknown country") }); err == nil {
+		t.Errorf("Expected error for unsupported value type.")
+	}
+}
+
+func TestItem_PatternAny(t *testing.T) {
+	patterns := []*regexp.Regexp{
+		regexp.MustCompile(`^[0-9]{5}$`),
+		regexp.MustCompile(`^[A-Z]{2}-[0-9]{3}$`),
+	}
+
+	tests := []struct {
+		value    interface{}
+		expected bool
+	}{
+		{"12345", true},
+		{"AB-123", true},
+		{"1234", false},
+		{"ab-123", false},
+		{"", false},
+	}
+
+	for i, test := range tests {
+		isValid, _, err := Check(test.value, func(item *Item) {
+			item.PatternAny(patterns, "invalid ID")
+		})
+		if err != nil {
+			t.Errorf("%d. Unexpected error: %s", i, err)
+		} else if isValid != test.expected {
+			t.Errorf("%d. Expected %t for %q, got %t", i, test.expected, test.value, isValid)
+		}
+	}
+
+	if isValid, _, err := Check("foo", func(item *Item) { item.PatternAny(nil, "invalid ID") }); err != nil || isValid {
+		t.Errorf("Expected no patterns to fail, got %t, %v", isValid, err)
+	}
+	if _, _, err := Check(1, func(item *Item) { item.PatternAny(patterns, "invalid ID") }); err == nil {
+		t.Errorf("Expected error for unsupported value type.")
+	}
+}
+
+func TestItem_Phone(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected bool
+		wantErr  bool
+	}{
+		{"+49 (0)30 1234567", true, false},
+		{"030-123 45 67", true, false},
+		{"112", true, false},
+		{"12", false, false},
+		{"+1234567890123456", false, false},
+		{"0049 30 12a4567", false, false},
+		{"++49 30 1234567", false, false},
+		{"", false, false},
+		{1234567, false, true},
+	}
+
+	for _, test := range tests {
+		item := &Item{value: test.value}
+		isValid, message, err := item.Phone("invalid").Validate()
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("Phone(%q): unexpected error %v", test.value, err)
+		} else if isValid != test.expected {
+			t.Errorf("Phone(%q): Expected %t, got %t", test.value, test.expected, isValid)
+		} else if !isValid && !test.wantErr && message != "invalid" {
+			t.Errorf("Phone(%q): Expected message %q, got %q", test.value, "invalid", message)
+		}
+	}
+}
+
+func TestItem_Confirmed(t *testing.T) {
+	tests := []struct {
+		password     string
+		confirmation string
+		expected     bool
+	}{
+		{"secret", "secret", true},
+		{"secret", "Secret", false},
+		{"secret", "", false},
+	}
+
+	for _, test := range tests {
+		items := New()
+		items.Add("password", test.password).Confirmed("passwords differ")
+		items.Add("password_confirmation", test.confirmation)
+
+		if messages, err := items.Validate(); err != nil {
+			t.Errorf("%q, %q: unexpected error %v", test.password, test.confirmation, err)
+		} else if isValid := len(messages) == 0; isValid != test.expected {
+			t.Errorf("%q, %q: Expected %t, got %t", test.password, test.confirmation, test.expected, isValid)
+		} else if !isValid && messages["password"] != "passwords differ" {
+			t.Errorf("Expected message for password, got %v", messages)
+		}
+	}
+
+	items := New()
+	items.Add("password", "secret").Confirmed("passwords differ")
+	if _, err := items.Validate(); err == nil {
+		t.Errorf("Expected error for missing confirmation item.")
+	}
+}
+
+func TestItem_FuncCtx(t *testing.T) {
+	type key struct{}
+	taken := map[string]bool{"foo@example.com": true}
+
+	fn := func(ctx context.Context, value interface{}) (bool, error) {
+		if ctx.Value(key{}) != "request" {
+			t.Errorf("Expected context to be passed to rule.")
+		}
+		return !taken[value.(string)], nil
+	}
+
+	ctx := context.WithValue(context.Background(), key{}, "request")
+
+	for value, expected := range map[string]bool{"foo@example.com": false, "bar@example.com": true} {
+		item := &Item{value: value}
+		if isValid, _, err := item.FuncCtx(fn, "taken").ValidateCtx(ctx); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		} else if isValid != expected {
+			t.Errorf("FuncCtx(%q): Expected %t, got %t", value, expected, isValid)
+		}
+	}
+
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	item := &Item{value: "bar@example.com"}
+	if _, _, err := item.FuncCtx(fn, "taken").ValidateCtx(cancelledCtx); err != context.Canceled {
+		t.Errorf("Expected error %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestItem_StrictEmailAddress(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    bool
+		expectedLax bool
+	}{
+		{"foo@example.com", true, true},
+		{"foo.bar+baz@mail.example.co.uk", true, true},
+		{"o'neil@example-domain.org", true, true},
+		{"a@b", false, true},
+		{"foo bar@example.com", false, true},
+		{"foo@example.c", false, true},
+		{".foo@example.com", false, true},
+		{"foo..bar@example.com", false, true},
+		{"foo@-example.com", false, true},
+		{"foo@example..com", false, true},
+		{"foo", false, false},
+		{"foo@bar@example.com", false, false},
+	}
+
+	for _, test := range tests {
+		if isValid, _, err := (&Item{value: test.value}).StrictEmailAddress("invalid").Validate(); err != nil {
+			t.Errorf("StrictEmailAddress(%q): unexpected error %v", test.value, err)
+		} else if isValid != test.expected {
+			t.Errorf("StrictEmailAddress(%q): Expected %t, got %t", test.value, test.expected, isValid)
+		}
+
+		if isValid, _, err := (&Item{value: test.value}).EmailAddress("invalid").Validate(); err != nil {
+			t.Errorf("EmailAddress(%q): unexpected error %v", test.value, err)
+		} else if isValid != test.expectedLax {
+			t.Errorf("EmailAddress(%q): Expected %t, got %t", test.value, test.expectedLax, isValid)
+		}
+	}
+}
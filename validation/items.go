@@ -1,6 +1,16 @@
 // Package validation provides validation for values.
 package validation
 
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync/atomic"
+
+	"github.com/ChristianSiegert/go-packages/i18n/languages"
+)
+
 // Items manages Item objects.
 type Items map[string]*Item
 
@@ -9,10 +19,17 @@ func New() Items {
 	return make(Items)
 }
 
+// addCount is the number of items added by Items.Add. It is used to order
+// items by the time they were added.
+var addCount uint64
+
 // Add adds an item whose value is to be validated. Validation rules must be
 // attached to the item itself.
 func (i Items) Add(name string, value interface{}) *Item {
 	item := &Item{
+		items: i,
+		name:  name,
+		order: atomic.AddUint64(&addCount, 1),
 		value: value,
 	}
 
@@ -20,20 +37,212 @@ func (i Items) Add(name string, value interface{}) *Item {
 	return item
 }
 
+// AddMulti adds multiple items at once. values is a map of item name as key and
+// the value to be validated as value. The returned map contains the created
+// items, so validation rules can be attached to each of them.
+func (i Items) AddMulti(values map[string]interface{}) map[string]*Item {
+	items := make(map[string]*Item, len(values))
+	for name, value := range values {
+		items[name] = i.Add(name, value)
+	}
+	return items
+}
+
+// AddStruct adds an item for each exported field of the struct s, which may
+// also be a pointer to a struct. Items are named after their field, prefixed
+// with prefix and a dot, e.g. “address.City” for prefix “address”. If prefix
+// is empty, items are named after their field only. Fields of nested structs
+// are added recursively, with the field’s name appended to the prefix.
+//
+// If a field has the tag “validate”, the tag’s value is used instead of the
+// field name. Fields with the tag value “-” are skipped.
+//
+// The returned map contains the created items, so validation rules can be
+// attached to each of them.
+func (i Items) AddStruct(prefix string, s interface{}) (map[string]*Item, error) {
+	v := reflect.Indirect(reflect.ValueOf(s))
+	if v.Kind() != reflect.Struct {
+		return nil, errors.New("validation.Items.AddStruct: argument must be a struct or a pointer to a struct")
+	}
+
+	items := make(map[string]*Item)
+	i.addStruct(items, prefix, v)
+	return items, nil
+}
+
+// addStruct adds the fields of struct v to i and items.
+func (i Items) addStruct(items map[string]*Item, prefix string, v reflect.Value) {
+	t := v.Type()
+
+	for j, k := 0, v.NumField(); j < k; j++ {
+		field := t.Field(j)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		if tag := field.Tag.Get("validate"); tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		value := v.Field(j)
+		if nested := reflect.Indirect(value); nested.Kind() == reflect.Struct && hasExportedFields(nested.Type()) {
+			i.addStruct(items, name, nested)
+			continue
+		}
+
+		items[name] = i.Add(name, value.Interface())
+	}
+}
+
+// hasExportedFields returns whether struct type t has exported fields. Structs
+// without exported fields, e.g. time.Time, are validated as a single value.
+func hasExportedFields(t reflect.Type) bool {
+	for j, k := 0, t.NumField(); j < k; j++ {
+		if t.Field(j).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
+
 // Validate validates all items.
 func (i Items) Validate() (Messages, error) {
-	var messages Messages
+	return i.ValidateCtx(context.Background())
+}
 
-	for name, item := range i {
-		if isValid, message, err := item.Validate(); err != nil {
+// ValidateCtx is like Validate, but passes ctx to rules created with
+// Item.FuncCtx.
+func (i Items) ValidateCtx(ctx context.Context) (Messages, error) {
+	messages, _, err := i.validate(ctx, ruleMessage)
+	return messages, err
+}
+
+// ValidateFirst validates items in the order they were added and stops at the
+// first invalid item, e.g. so rules that query a database are not checked if a
+// simple rule of an earlier item already failed. The returned messages contain
+// at most the message of that item. Items added with AddMulti are added in
+// random order.
+func (i Items) ValidateFirst() (Messages, error) {
+	return i.ValidateFirstCtx(context.Background())
+}
+
+// ValidateFirstCtx is like ValidateFirst, but passes ctx to rules created
+// with Item.FuncCtx.
+func (i Items) ValidateFirstCtx(ctx context.Context) (Messages, error) {
+	items := make([]*Item, 0, len(i))
+	for _, item := range i {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(a, b int) bool {
+		return items[a].order < items[b].order
+	})
+
+	for _, item := range items {
+		if failed, _, err := item.validate(ctx); err != nil {
 			return nil, err
-		} else if !isValid {
+		} else if failed != nil {
+			return Messages{item.name: failed.Message}, nil
+		}
+	}
+	return nil, nil
+}
+
+// ValidateLanguage validates all items like Validate, but treats the messages
+// of rules as translation IDs and returns their translation in language, see
+// languages.Language.T. The rule’s Args are provided to the translation as
+// data under the key “Args”, e.g. “At most {{index .Args 0}} characters.” for
+// a rule created by MaxLength. If language is nil, messages are returned as is.
+func (i Items) ValidateLanguage(language *languages.Language) (Messages, error) {
+	messages, _, err := i.validate(context.Background(), func(rule *Rule) string {
+		return translateMessage(language, rule)
+	})
+	return messages, err
+}
+
+// ValidateWarnings validates all items like Validate. Additionally, it returns
+// the warnings of valid items whose rules with SeverityWarning failed. Items
+// with only warnings do not appear in messages.
+func (i Items) ValidateWarnings() (messages, warnings Messages, err error) {
+	return i.ValidateWarningsCtx(context.Background())
+}
+
+// ValidateWarningsCtx is like ValidateWarnings, but passes ctx to rules
+// created with Item.FuncCtx.
+func (i Items) ValidateWarningsCtx(ctx context.Context) (messages, warnings Messages, err error) {
+	return i.validate(ctx, ruleMessage)
+}
+
+// validate implements the Validate… methods. message returns the message of a
+// failing rule.
+func (i Items) validate(ctx context.Context, message func(rule *Rule) string) (messages, warnings Messages, err error) {
+	for name, item := range i {
+		failed, warned, err := item.validate(ctx)
+		if err != nil {
+			return nil, nil, err
+		} else if failed != nil {
 			if messages == nil {
 				messages = make(Messages)
 			}
-			messages[name] = message
+			messages[name] = message(failed)
+		} else if warned != nil {
+			if warnings == nil {
+				warnings = make(Messages)
+			}
+			warnings[name] = message(warned)
+		}
+	}
+
+	return messages, warnings, nil
+}
+
+// translateMessage returns the translation of rule’s message in language. If
+// language is nil, it returns the message as is.
+func translateMessage(language *languages.Language, rule *Rule) string {
+	if language == nil {
+		return rule.Message
+	}
+	return language.T(rule.Message, map[string]interface{}{"Args": rule.Args})
+}
+
+// ValidateErr validates all items like Validate, but returns a single error. If
+// items failed validation, the error is of type *Error and contains the
+// validation error messages.
+func (i Items) ValidateErr() error {
+	messages, err := i.Validate()
+	if err != nil {
+		return err
+	} else if len(messages) > 0 {
+		return &Error{Messages: messages}
+	}
+	return nil
+}
+
+// ValidateCoerced validates all items like Validate. Additionally, it returns
+// the coerced value of each valid item, see Item.Coerced.
+func (i Items) ValidateCoerced() (map[string]interface{}, Messages, error) {
+	messages, err := i.Validate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := make(map[string]interface{}, len(i)-len(messages))
+	for name, item := range i {
+		if _, ok := messages[name]; ok {
+			continue
+		}
+
+		value, err := item.Coerced()
+		if err != nil {
+			return nil, nil, err
 		}
+		values[name] = value
 	}
 
-	return messages, nil
+	return values, messages, nil
 }
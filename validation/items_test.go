@@ -1,6 +1,15 @@
 package validation
 
-import "testing"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/ChristianSiegert/go-packages/i18n/languages"
+)
 
 func TestItems_Add(t *testing.T) {
 	items := New()
@@ -19,3 +28,217 @@ func TestItems_Add(t *testing.T) {
 		t.Errorf("Expected value of item 2 to be %q", "value2")
 	}
 }
+
+func TestItems_AddMulti(t *testing.T) {
+	items := New()
+	result := items.AddMulti(map[string]interface{}{
+		"name1": 1,
+		"name2": "value2",
+	})
+
+	if len(items) != 2 {
+		t.Errorf("Expected 2 items, got %d", len(items))
+	} else if len(result) != 2 {
+		t.Errorf("Expected 2 returned items, got %d", len(result))
+	} else if result["name1"] != items["name1"] || result["name2"] != items["name2"] {
+		t.Errorf("Expected returned items to be the added items.")
+	} else if items["name1"].value != 1 {
+		t.Errorf("Expected value of item 1 to be %d", 1)
+	} else if items["name2"].value != "value2" {
+		t.Errorf("Expected value of item 2 to be %q", "value2")
+	}
+}
+
+func TestItems_AddStruct(t *testing.T) {
+	type Address struct {
+		City    string
+		ZipCode string `validate:"zip"`
+	}
+
+	type Order struct {
+		Address  *Address
+		Created  time.Time
+		Email    string `validate:"email"`
+		Internal string `validate:"-"`
+		Items    int
+		note     string
+	}
+
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	order := &Order{
+		Address: &Address{City: "Berlin", ZipCode: "10115"},
+		Created: created,
+		Email:   "foo@example.com",
+		Items:   3,
+		note:    "note",
+	}
+
+	items := New()
+	result, err := items.AddStruct("order", order)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"order.Address.City": "Berlin",
+		"order.Address.zip":  "10115",
+		"order.Created":      created,
+		"order.email":        "foo@example.com",
+		"order.Items":        3,
+	}
+
+	values := make(map[string]interface{}, len(items))
+	for name, item := range items {
+		values[name] = item.value
+		if result[name] != item {
+			t.Errorf("Expected returned item %q to be the added item.", name)
+		}
+	}
+
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected\n%v\ngot\n%v", expected, values)
+	} else if len(result) != len(items) {
+		t.Errorf("Expected %d returned items, got %d", len(items), len(result))
+	}
+
+	if _, err := items.AddStruct("", "foo"); err == nil {
+		t.Errorf("Expected error for non-struct argument.")
+	}
+
+	items = New()
+	if _, err := items.AddStruct("", Address{City: "Berlin"}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if _, ok := items["City"]; !ok {
+		t.Errorf("Expected item without prefix, got %v", items)
+	}
+}
+
+func TestItems_ValidateErr(t *testing.T) {
+	items := New()
+	items.Add("name", "foo").Required("required")
+
+	if err := items.ValidateErr(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	items.Add("email", "").Required("required")
+
+	var validationErr *Error
+	if err := items.ValidateErr(); !errors.As(fmt.Errorf("wrapped: %w", err), &validationErr) {
+		t.Fatalf("Expected *Error, got %v", err)
+	} else if expected := (Messages{"email": "required"}); !reflect.DeepEqual(validationErr.Messages, expected) {
+		t.Errorf("Expected %v, got %v", expected, validationErr.Messages)
+	}
+
+	// Errors of rules are returned as is.
+	items.Add("age", "foo").Max(1, "too large")
+	if err := items.ValidateErr(); err == nil || errors.As(err, new(*Error)) {
+		t.Errorf("Expected rule error, got %v", err)
+	}
+}
+
+func TestItems_ValidateFirst(t *testing.T) {
+	var called bool
+
+	items := New()
+	items.Add("b", "").Required("b required")
+	items.Add("a", "").Required("a required")
+	items.Add("c", "foo").Func(func(value interface{}) (bool, error) {
+		called = true
+		return true, nil
+	}, "c invalid")
+
+	if messages, err := items.ValidateFirst(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if expected := (Messages{"b": "b required"}); !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	} else if called {
+		t.Errorf("Expected rules of later items not to be checked.")
+	}
+
+	items = New()
+	items.Add("a", "foo").Required("a required")
+	if messages, err := items.ValidateFirst(); err != nil || messages != nil {
+		t.Errorf("Expected no messages, got %v, %v", messages, err)
+	}
+}
+
+func TestItems_ValidateLanguage(t *testing.T) {
+	de := languages.NewLanguage("de", "Deutsch")
+	if err := de.SetMulti(map[string]interface{}{
+		"required":   "Pflichtfeld",
+		"max-length": "Höchstens {{index .Args 0}} Zeichen",
+	}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	items := New()
+	items.Add("name", "").Required("required")
+	items.Add("city", "Berlin").MaxLength(3, "max-length")
+	items.Add("zip", "foo").Pattern(regexp.MustCompile("^[0-9]+$"), "untranslated")
+
+	expected := Messages{
+		"city": "Höchstens 3 Zeichen",
+		"name": "Pflichtfeld",
+		"zip":  "untranslated",
+	}
+	if messages, err := items.ValidateLanguage(de); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+
+	expected = Messages{"city": "max-length", "name": "required", "zip": "untranslated"}
+	if messages, err := items.ValidateLanguage(nil); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+}
+
+func TestItems_ValidateWarnings(t *testing.T) {
+	items := New()
+	items.Add("password", "secret").Required("required").MinLength(12, "weak password").Warn()
+	items.Add("short", "").Required("required").MinLength(12, "weak password").Warn()
+	items.Add("strong", "correct horse battery").MinLength(12, "weak password").Warn()
+
+	messages, warnings, err := items.ValidateWarnings()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if expected := (Messages{"short": "required"}); !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Expected messages %v, got %v", expected, messages)
+	} else if expected := (Messages{"password": "weak password"}); !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("Expected warnings %v, got %v", expected, warnings)
+	}
+
+	// Validate ignores warnings.
+	if messages, err := items.Validate(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if expected := (Messages{"short": "required"}); !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Expected messages %v, got %v", expected, messages)
+	}
+}
+
+func TestItems_ValidateCoerced(t *testing.T) {
+	items := New()
+	items.Add("price", "12.5").Number("invalid number")
+	items.Add("quantity", "foo").Number("invalid number")
+	items.Add("name", "bar").Required("required")
+
+	values, messages, err := items.ValidateCoerced()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expectedValues := map[string]interface{}{
+		"price": 12.5,
+		"name":  "bar",
+	}
+	expectedMessages := Messages{"quantity": "invalid number"}
+
+	if !reflect.DeepEqual(values, expectedValues) {
+		t.Errorf("Expected %#v, got %#v", expectedValues, values)
+	} else if !reflect.DeepEqual(messages, expectedMessages) {
+		t.Errorf("Expected %#v, got %#v", expectedMessages, messages)
+	}
+}
@@ -1,6 +1,10 @@
 package validation
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
 
 // Messages is a map whose keys are item names and whose values are validation
 // error messages. The map only contains the names of items that failed
@@ -11,3 +15,25 @@ type Messages map[string]string
 func (m Messages) Error() string {
 	return fmt.Sprintf("%#v", m)
 }
+
+// Error is returned by Items.ValidateErr if items failed validation. Use
+// errors.As to retrieve it and access the validation error messages.
+type Error struct {
+	Messages Messages
+}
+
+// Error implements the error interface. It lists the names of the items that
+// failed validation together with their message, sorted by name.
+func (e *Error) Error() string {
+	names := make([]string, 0, len(e.Messages))
+	for name := range e.Messages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pieces := make([]string, 0, len(names))
+	for _, name := range names {
+		pieces = append(pieces, name+": "+e.Messages[name])
+	}
+	return "validation failed: " + strings.Join(pieces, "; ")
+}
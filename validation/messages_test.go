@@ -13,3 +13,15 @@ func TestMessages_Error(t *testing.T) {
 		t.Fatalf("Expected %s, got %s", expected, result)
 	}
 }
+
+func TestError_Error(t *testing.T) {
+	e := &Error{Messages: Messages{
+		"item2": "message 2",
+		"item1": "message 1",
+	}}
+
+	expected := "validation failed: item1: message 1; item2: message 2"
+	if result := e.Error(); result != expected {
+		t.Fatalf("Expected %s, got %s", expected, result)
+	}
+}
@@ -1,18 +1,42 @@
 package validation
 
+import "context"
+
+// Severities of rules.
+const (
+	// SeverityError means a value that fails the rule is invalid.
+	SeverityError = iota
+
+	// SeverityWarning means a value that fails the rule is valid, but the
+	// user should be informed, e.g. about a weak password.
+	SeverityWarning
+)
+
 // Rule contains the validation function and information about it.
 type Rule struct {
 	// Arguments that Func was called with.
 	Args []interface{}
 
+	// Coerce converts a valid value to its typed equivalent, e.g. a numeric
+	// string to float64. It is nil if the rule does not coerce values.
+	Coerce func(interface{}) (interface{}, error)
+
 	// Func returns whether the argument is valid, or that an error occurred
 	// while validating. A returned error does not mean the argument is invalid,
 	// it solely means something went wrong while validating.
 	Func func(interface{}) (bool, error)
 
+	// FuncCtx is like Func, but receives the context passed to ValidateCtx.
+	// If FuncCtx is set, it is used instead of Func.
+	FuncCtx func(context.Context, interface{}) (bool, error)
+
 	// Message that informs the user if her input is invalid.
 	Message string
 
+	// Severity is SeverityError or SeverityWarning. It is SeverityError by
+	// default, see Item.Warn.
+	Severity int
+
 	// Type gives information about the rule type, e.g. RuleTypeMaxLength means
 	// it is a rule for checking maximum length. A value of 0 means no type is
 	// provided.
@@ -0,0 +1,86 @@
+package webapps
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// LogFormat is the format of access log entries.
+type LogFormat int
+
+// Formats of access log entries.
+const (
+	// LogFormatText writes one line per request, e.g.
+	// “2006/01/02 15:04:05 [abc-123] 192.0.2.1:1234 GET "/foo" 200 512 1.5ms”.
+	// The path is quoted, so it cannot add lines to the log.
+	LogFormatText LogFormat = iota
+
+	// LogFormatJSON writes one JSON object per line and request, for
+	// ingestion into log aggregators, see accessLogEntry.
+	LogFormatJSON
+)
+
+// accessLogEntry is an access log entry in format LogFormatJSON.
+type accessLogEntry struct {
+	Bytes      int     `json:"bytes"`
+	DurationMs float64 `json:"durationMs"`
+	Method     string  `json:"method"`
+	Path       string  `json:"path"`
+	RemoteAddr string  `json:"remoteAddr"`
+	RequestID  string  `json:"requestId,omitempty"`
+	Status     int     `json:"status"`
+	Time       string  `json:"time"`
+}
+
+// AccessLogMiddleware returns a middleware that writes an entry to output for
+// each request after it was handled. An entry contains the request method,
+// path, response status code, number of response body bytes, duration,
+// request ID and remote address. format selects the format of entries. Pass
+// the middleware to WebApp.Use, so requests for which no route exists and
+// error responses written by OnError and OnPanic are logged, too. The request
+// ID is only logged if RequestIDMiddleware is used.
+func AccessLogMiddleware(output io.Writer, format LogFormat) func(http.Handler) http.Handler {
+	flags := log.Ldate | log.Ltime
+	if format == LogFormatJSON {
+		flags = 0
+	}
+	accessLogger := log.New(output, "", flags)
+
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: writer}
+			handler.ServeHTTP(sw, request)
+
+			duration := time.Since(start)
+			status := sw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			if format == LogFormatJSON {
+				entry, err := json.Marshal(&accessLogEntry{
+					Bytes:      sw.bytes,
+					DurationMs: float64(duration) / float64(time.Millisecond),
+					Method:     request.Method,
+					Path:       request.URL.Path,
+					RemoteAddr: request.RemoteAddr,
+					RequestID:  writer.Header().Get(RequestIDHeader),
+					Status:     status,
+					Time:       start.UTC().Format(time.RFC3339Nano),
+				})
+				if err != nil {
+					logger.Printf("access log: %s", err)
+					return
+				}
+				accessLogger.Print(string(entry))
+				return
+			}
+
+			accessLogger.Printf("%s%s %s %q %d %d %s", logRequestID(writer), request.RemoteAddr, request.Method, request.URL.Path, status, sw.bytes, duration)
+		})
+	}
+}
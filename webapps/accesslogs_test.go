@@ -0,0 +1,79 @@
+package webapps
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestAccessLogMiddleware_json(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected accessLogEntry
+	}{
+		{"/foo", accessLogEntry{Bytes: 3, Method: http.MethodGet, Path: "/foo", RemoteAddr: "192.0.2.1:1234", RequestID: "abc-123", Status: http.StatusCreated}},
+		{"/missing", accessLogEntry{Bytes: 19, Method: http.MethodGet, Path: "/missing", RemoteAddr: "192.0.2.1:1234", RequestID: "", Status: http.StatusNotFound}},
+	}
+
+	for i, test := range tests {
+		var buffer bytes.Buffer
+		webApp := newAccessLogWebApp(&buffer, LogFormatJSON)
+
+		request := httptest.NewRequest(http.MethodGet, test.path, nil)
+		if test.expected.RequestID != "" {
+			request.Header.Set(RequestIDHeader, test.expected.RequestID)
+		}
+		webApp.Handler().ServeHTTP(httptest.NewRecorder(), request)
+
+		var entry accessLogEntry
+		if err := json.Unmarshal(buffer.Bytes(), &entry); err != nil {
+			t.Errorf("%d. Decoding %q failed unexpectedly: %s", i, buffer.String(), err)
+			continue
+		}
+
+		if entry.Time == "" {
+			t.Errorf("%d. Expected time in %q", i, buffer.String())
+		}
+		entry.DurationMs, entry.Time = 0, ""
+		if entry != test.expected {
+			t.Errorf("%d. Expected %+v, got %+v", i, test.expected, entry)
+		}
+	}
+}
+
+func TestAccessLogMiddleware_text(t *testing.T) {
+	var buffer bytes.Buffer
+	webApp := newAccessLogWebApp(&buffer, LogFormatText)
+
+	request := httptest.NewRequest(http.MethodGet, "/foo", nil)
+	request.Header.Set(RequestIDHeader, "abc-123")
+	webApp.Handler().ServeHTTP(httptest.NewRecorder(), request)
+
+	if expected := `[abc-123] 192.0.2.1:1234 GET "/foo" 201 3 `; !strings.Contains(buffer.String(), expected) {
+		t.Errorf("Expected %q in %q", expected, buffer.String())
+	}
+
+	buffer.Reset()
+	webApp.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/foo%0Abar", nil))
+
+	if expected := `GET "/foo\nbar" 404 `; !strings.Contains(buffer.String(), expected) {
+		t.Errorf("Expected %q in %q", expected, buffer.String())
+	}
+}
+
+func newAccessLogWebApp(buffer *bytes.Buffer, format LogFormat) *WebApp {
+	webApp := New("", "")
+	webApp.Use(AccessLogMiddleware(buffer, format))
+	webApp.Middleware(RequestIDMiddleware())
+	webApp.Route("/foo", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		writer.WriteHeader(http.StatusCreated)
+		_, err := writer.Write([]byte("foo"))
+		return err
+	}, http.MethodGet)
+	return webApp
+}
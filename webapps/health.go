@@ -0,0 +1,32 @@
+package webapps
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// Health registers a health check endpoint at path, e.g. “/healthz” or
+// “/readyz”. A GET or HEAD request runs checks in order, e.g. a function that
+// pings the database. If all checks pass, the response has status code 200
+// and the body “ok”. Otherwise, the response has status code 503 and the body
+// contains the error of the first failing check. Middlewares apply as with
+// Route.
+func (w *WebApp) Health(path string, checks ...func() error) {
+	w.Route(path, func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		writer.Header().Set("Cache-Control", "no-store")
+		writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		for _, check := range checks {
+			// A failing check is reported to the client, not to OnError.
+			if err := check(); err != nil {
+				writer.WriteHeader(http.StatusServiceUnavailable)
+				_, writeErr := writer.Write([]byte(err.Error()))
+				return writeErr
+			}
+		}
+
+		_, err := writer.Write([]byte("ok"))
+		return err
+	}, http.MethodGet, http.MethodHead)
+}
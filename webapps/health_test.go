@@ -0,0 +1,38 @@
+package webapps
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebApp_Health(t *testing.T) {
+	var dbErr error
+	webApp := New("", "")
+	webApp.Health("/healthz")
+	webApp.Health("/readyz", func() error { return nil }, func() error { return dbErr })
+
+	tests := []struct {
+		path         string
+		dbErr        error
+		expectedCode int
+		expectedBody string
+	}{
+		{"/healthz", nil, http.StatusOK, "ok"},
+		{"/readyz", nil, http.StatusOK, "ok"},
+		{"/readyz", errors.New("database unreachable"), http.StatusServiceUnavailable, "database unreachable"},
+	}
+
+	for i, test := range tests {
+		dbErr = test.dbErr
+		recorder := httptest.NewRecorder()
+		webApp.Router.ServeHTTP(recorder, httptest.NewRequest("GET", test.path, nil))
+
+		if recorder.Code != test.expectedCode {
+			t.Errorf("%d. Expected status code %d, got %d", i, test.expectedCode, recorder.Code)
+		} else if body := recorder.Body.String(); body != test.expectedBody {
+			t.Errorf("%d. Expected body %q, got %q", i, test.expectedBody, body)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package webapps
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// MaxBodyMiddleware returns a middleware that limits request bodies to limit
+// bytes. Requests whose Content-Length header exceeds limit are answered with
+// status code 413 without calling the Handle. Otherwise, reading more than
+// limit bytes from the body, e.g. by http.Request.ParseForm, fails with an
+// *http.MaxBytesError. If the Handle returns that error and has not written
+// the response header yet, the response has status code 413, too.
+//
+// To limit all routes, pass the middleware to WebApp.Middleware. To limit a
+// single route, wrap its Handle, e.g. MaxBodyMiddleware(1 << 20)(handle).
+func MaxBodyMiddleware(limit int64) Middleware {
+	return func(handle Handle) Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+			if request.ContentLength > limit {
+				http.Error(writer, "request entity too large", http.StatusRequestEntityTooLarge)
+				return nil
+			}
+
+			request.Body = http.MaxBytesReader(writer, request.Body, limit)
+
+			err := handle(writer, request, params)
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) && !headerWritten(writer) {
+				http.Error(writer, "request entity too large", http.StatusRequestEntityTooLarge)
+				return nil
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package webapps
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestMaxBodyMiddleware(t *testing.T) {
+	webApp := New("", "")
+	webApp.Middleware(MaxBodyMiddleware(5))
+	webApp.Route("/", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		if err := request.ParseForm(); err != nil {
+			return err
+		}
+		_, err := writer.Write([]byte(request.PostFormValue("a")))
+		return err
+	}, http.MethodPost)
+
+	tests := []struct {
+		body          string
+		contentLength int64
+		expectedCode  int
+		expectedBody  string
+	}{
+		{"a=foo", 5, http.StatusOK, "foo"},
+		{"a=foobar", 8, http.StatusRequestEntityTooLarge, "request entity too large\n"},
+		// Unknown content length, so the limit is hit while reading.
+		{"a=foobar", -1, http.StatusRequestEntityTooLarge, "request entity too large\n"},
+	}
+
+	for i, test := range tests {
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body))
+		request.ContentLength = test.contentLength
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		recorder := httptest.NewRecorder()
+		webApp.Router.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expectedCode {
+			t.Errorf("%d. Expected status code %d, got %d", i, test.expectedCode, recorder.Code)
+		} else if body := recorder.Body.String(); body != test.expectedBody {
+			t.Errorf("%d. Expected body %q, got %q", i, test.expectedBody, body)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package webapps
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// RequestIDHeader is the name of the header that RequestIDMiddleware reads the
+// request ID from and writes it to.
+var RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the maximum length of a request ID that is accepted
+// from a client.
+const maxRequestIDLength = 128
+
+// requestIDKey is the context key under which the request ID is stored.
+type requestIDKey struct{}
+
+// RequestIDMiddleware returns a middleware that tags each request with an ID,
+// e.g. for finding all log entries of a request. The ID is taken from the
+// request header RequestIDHeader, e.g. if a proxy already assigned one, or
+// generated randomly if the header is missing or invalid. The ID is stored in
+// the request’s context, see RequestIDFromContext, and sent to the client in
+// the response header RequestIDHeader.
+func RequestIDMiddleware() Middleware {
+	return func(handle Handle) Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+			id := request.Header.Get(RequestIDHeader)
+			if !isValidRequestID(id) {
+				var err error
+				if id, err = generateRequestID(); err != nil {
+					return err
+				}
+			}
+
+			writer.Header().Set(RequestIDHeader, id)
+			ctx := context.WithValue(request.Context(), requestIDKey{}, id)
+			return handle(writer, request.WithContext(ctx), params)
+		}
+	}
+}
+
+// RequestIDFromContext returns the request ID stored in ctx by
+// RequestIDMiddleware, or an empty string if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// generateRequestID returns a random, hex-encoded request ID.
+func generateRequestID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// isValidRequestID returns whether id can be used as request ID. It must not
+// be empty, not be longer than maxRequestIDLength and only contain printable
+// ASCII characters, so it cannot break log lines.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package webapps
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var buffer bytes.Buffer
+	logger.SetOutput(&buffer)
+	defer logger.SetOutput(os.Stderr)
+
+	var contextID string
+	webApp := New("", "")
+	webApp.Middleware(RequestIDMiddleware())
+	webApp.Route("/", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		contextID = RequestIDFromContext(request.Context())
+		return errors.New("foo")
+	}, http.MethodGet)
+
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"abc-123", "abc-123"},
+		{"", ""},
+		{"with space", ""},
+		{strings.Repeat("a", maxRequestIDLength+1), ""},
+	}
+
+	for i, test := range tests {
+		buffer.Reset()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set(RequestIDHeader, test.header)
+
+		recorder := httptest.NewRecorder()
+		webApp.Router.ServeHTTP(recorder, request)
+		id := recorder.Header().Get(RequestIDHeader)
+
+		if test.expected != "" && id != test.expected {
+			t.Errorf("%d. Expected ID %q, got %q", i, test.expected, id)
+		} else if test.expected == "" && (len(id) != 32 || id == test.header) {
+			t.Errorf("%d. Expected generated ID, got %q", i, id)
+		} else if contextID != id {
+			t.Errorf("%d. Expected ID %q in context, got %q", i, id, contextID)
+		} else if !strings.Contains(buffer.String(), "["+id+"]") {
+			t.Errorf("%d. Expected ID %q in log %q", i, id, buffer.String())
+		}
+	}
+}
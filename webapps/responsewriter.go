@@ -0,0 +1,123 @@
+package webapps
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// responseWriter wraps http.ResponseWriter to record whether the response
+// header was already written.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+// Flush sends buffered data to the client if the underlying
+// http.ResponseWriter supports flushing.
+func (r *responseWriter) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		r.wroteHeader = true
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection if the underlying
+// http.ResponseWriter supports it, see http.Hijacker. The response is then
+// considered started.
+func (r *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	r.wroteHeader = true
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the underlying http.ResponseWriter. It is used by
+// http.ResponseController.
+func (r *responseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Write writes data to the response.
+func (r *responseWriter) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(data)
+}
+
+// WriteHeader writes the response header with the provided status code.
+func (r *responseWriter) WriteHeader(statusCode int) {
+	r.wroteHeader = true
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// headerWritten returns whether the response header of writer was already
+// written. If writer was not wrapped by WebApp, it returns false.
+func headerWritten(writer http.ResponseWriter) bool {
+	if r, ok := writer.(*responseWriter); ok {
+		return r.wroteHeader
+	}
+	return false
+}
+
+// headResponseWriter wraps http.ResponseWriter to discard the response body,
+// so GET Handles can respond to HEAD requests. Headers and the status code are
+// written as usual.
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+// Unwrap returns the underlying http.ResponseWriter. It is used by
+// http.ResponseController.
+func (h *headResponseWriter) Unwrap() http.ResponseWriter {
+	return h.ResponseWriter
+}
+
+// Write discards data and reports it as written.
+func (h *headResponseWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+// statusWriter wraps http.ResponseWriter to record the status code and the
+// number of body bytes written.
+type statusWriter struct {
+	http.ResponseWriter
+	bytes  int
+	status int
+}
+
+// Flush sends buffered data to the client if the underlying
+// http.ResponseWriter supports flushing.
+func (s *statusWriter) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		if s.status == 0 {
+			s.status = http.StatusOK
+		}
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter. It is used by
+// http.ResponseController.
+func (s *statusWriter) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// Write writes data to the response.
+func (s *statusWriter) Write(data []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(data)
+	s.bytes += n
+	return n, err
+}
+
+// WriteHeader writes the response header with the provided status code.
+func (s *statusWriter) WriteHeader(statusCode int) {
+	if s.status == 0 {
+		s.status = statusCode
+	}
+	s.ResponseWriter.WriteHeader(statusCode)
+}
@@ -0,0 +1,71 @@
+package webapps
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Shutdown gracefully shuts down the server started by Start, StartWithTLS,
+// Serve or ServeTLS. It stops accepting connections and waits for active
+// requests to finish until ctx is done, see http.Server.Shutdown. If no server
+// was started, Shutdown does nothing.
+func (w *WebApp) Shutdown(ctx context.Context) error {
+	w.mutex.Lock()
+	server := w.server
+	w.mutex.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
+
+// StartWithGracefulShutdown starts the HTTP server like Start. When the
+// process receives SIGINT or SIGTERM, it calls Shutdown, waiting at most
+// timeout for active requests to finish, and returns. It returns nil if the
+// server was shut down completely.
+func (w *WebApp) StartWithGracefulShutdown(timeout time.Duration) error {
+	listener, err := net.Listen("tcp", w.serverHost+":"+w.serverPort)
+	if err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	return w.serveUntil(listener, signals, timeout)
+}
+
+// serveUntil serves on listener until a value is received from signals, then
+// shuts the server down within timeout. The server is registered before
+// serving starts, so a signal received early still shuts it down.
+func (w *WebApp) serveUntil(listener net.Listener, signals <-chan os.Signal, timeout time.Duration) error {
+	server := w.newServer(listener)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-signals:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := w.Shutdown(ctx); err != nil {
+		return err
+	}
+	if err := <-errs; err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
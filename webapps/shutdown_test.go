@@ -0,0 +1,101 @@
+package webapps
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestWebApp_serveUntil(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Creating listener failed unexpectedly: %s", err)
+	}
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+
+	webApp := New("", "")
+	webApp.Route("/", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		close(started)
+		<-finish
+		_, err := writer.Write([]byte("foo"))
+		return err
+	}, "GET")
+
+	signals := make(chan os.Signal, 1)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- webApp.serveUntil(listener, signals, time.Second)
+	}()
+
+	responses := make(chan *http.Response, 1)
+	go func() {
+		response, err := http.Get("http://" + listener.Addr().String() + "/")
+		if err != nil {
+			t.Errorf("Request failed unexpectedly: %s", err)
+			close(responses)
+			return
+		}
+		response.Body.Close()
+		responses <- response
+	}()
+
+	<-started
+	signals <- os.Interrupt
+
+	select {
+	case err := <-errs:
+		t.Fatalf("Expected serveUntil to wait for the active request, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(finish)
+	if response := <-responses; response != nil && response.StatusCode != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, response.StatusCode)
+	}
+
+	select {
+	case err := <-errs:
+		if err != nil {
+			t.Errorf("Expected nil, got %s", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected serveUntil to return after shutdown.")
+	}
+}
+
+func TestWebApp_serveUntil_early(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Creating listener failed unexpectedly: %s", err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signals <- os.Interrupt
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- New("", "").serveUntil(listener, signals, time.Second)
+	}()
+
+	select {
+	case err := <-errs:
+		if err != nil {
+			t.Errorf("Expected nil, got %s", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected serveUntil to return after a signal received before serving.")
+	}
+}
+
+func TestWebApp_Shutdown_notStarted(t *testing.T) {
+	if err := New("", "").Shutdown(context.Background()); err != nil {
+		t.Errorf("Expected nil, got %s", err)
+	}
+}
@@ -2,11 +2,14 @@
 package webapps
 
 import (
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"path"
 	"runtime"
+	"sync"
 
 	"github.com/julienschmidt/httprouter"
 )
@@ -23,17 +26,28 @@ type Middleware func(Handle) Handle
 // underlying router and its settings. OnError and OnPanic can be overwritten
 // by custom functions to handle errors and panics.
 type WebApp struct {
-	middlewares []Middleware
+	// HandleHEAD determines whether Route also registers a Handle for HEAD
+	// requests when it registers a Handle for GET requests and HEAD is not
+	// among the provided methods. HEAD requests are then handled by the GET
+	// Handle, but the response body is discarded.
+	HandleHEAD bool
+
+	handlerMiddlewares []func(http.Handler) http.Handler
+	middlewares        []Middleware
 
 	// OnError is called after a Handle returned an error.
 	OnError func(writer http.ResponseWriter, request *http.Request, params httprouter.Params, err error)
 
-	// OnPanic is called after a Handle panicked.
+	// OnPanic is called after a Handle panicked, unless it panicked with
+	// http.ErrAbortHandler.
 	OnPanic func(writer http.ResponseWriter, request *http.Request, params httprouter.Params, recoveryInfo interface{})
 
 	// Router is the underlying router.
 	Router *httprouter.Router
 
+	listener   net.Listener
+	mutex      sync.Mutex
+	server     *http.Server
 	serverHost string
 	serverPort string
 }
@@ -51,22 +65,92 @@ func New(host, port string) *WebApp {
 	}
 }
 
+// Addr returns the address the web app listens on, e.g. “127.0.0.1:8080”. If
+// the port passed to New is “0”, Addr returns the port that was chosen by the
+// system. Before Start, StartWithTLS, Serve or ServeTLS was called, Addr
+// returns an empty string.
+func (w *WebApp) Addr() string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.listener == nil {
+		return ""
+	}
+	return w.listener.Addr().String()
+}
+
 // Middleware adds a function that is executed before any Handle is executed.
 // Middlewares added after calling Route are ignored.
 func (w *WebApp) Middleware(middleware Middleware) {
 	w.middlewares = append(w.middlewares, middleware)
 }
 
-// Route associates a URL path with a Handle.
+// Use adds a standard net/http middleware that wraps the whole router, e.g.
+// for metrics or tracing that must also see requests for which no route
+// exists. Unlike middlewares added with Middleware, it is called before the
+// router selects a route. Middlewares are called in the order they were added.
+// Middlewares added after the server started are ignored.
+func (w *WebApp) Use(middleware func(http.Handler) http.Handler) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.handlerMiddlewares = append(w.handlerMiddlewares, middleware)
+}
+
+// Handler returns Router wrapped by the middlewares added with Use. It is the
+// handler that serves requests, and useful for testing or for serving the web
+// app with a custom http.Server.
+func (w *WebApp) Handler() http.Handler {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.handler()
+}
+
+// handler implements Handler. The caller must hold w.mutex.
+func (w *WebApp) handler() http.Handler {
+	var handler http.Handler = w.Router
+	for i := len(w.handlerMiddlewares) - 1; i >= 0; i-- {
+		handler = w.handlerMiddlewares[i](handler)
+	}
+	return handler
+}
+
+// httpMethods contains the HTTP methods accepted by Route.
+var httpMethods = map[string]bool{
+	http.MethodConnect: true,
+	http.MethodDelete:  true,
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPatch:   true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodTrace:   true,
+}
+
+// Route associates a URL path with a Handle for each of the provided HTTP
+// methods, e.g. http.MethodGet. Route panics if no method is provided or if a
+// method is not a recognized HTTP method, so mistakes surface at startup. See
+// HandleHEAD for handling HEAD requests with GET Handles.
 func (w *WebApp) Route(path string, handle Handle, methods ...string) {
+	validateMethods(path, methods)
+
 	for _, method := range methods {
 		for _, middleware := range w.middlewares {
 			handle = middleware(handle)
 		}
 
 		h := func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			writer = &responseWriter{ResponseWriter: writer}
+
 			defer func() {
 				if r := recover(); r != nil {
+					// http.ErrAbortHandler aborts the request without
+					// logging, so let net/http handle it.
+					if r == http.ErrAbortHandler {
+						panic(r)
+					}
 					w.OnPanic(writer, request, params, r)
 				}
 			}()
@@ -77,28 +161,110 @@ func (w *WebApp) Route(path string, handle Handle, methods ...string) {
 		}
 
 		w.Router.Handle(method, path, h)
+
+		if method == http.MethodGet && w.HandleHEAD && !containsMethod(methods, http.MethodHead) {
+			w.Router.Handle(http.MethodHead, path, func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+				h(&headResponseWriter{ResponseWriter: writer}, request, params)
+			})
+		}
+	}
+}
+
+// containsMethod returns whether methods contains method.
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
 	}
+	return false
+}
+
+// validateMethods panics if methods is empty or contains a method that is not a
+// recognized HTTP method.
+func validateMethods(path string, methods []string) {
+	if len(methods) == 0 {
+		panic(fmt.Sprintf("webapps: no HTTP method provided for route %q", path))
+	}
+
+	for _, method := range methods {
+		if !httpMethods[method] {
+			panic(fmt.Sprintf("webapps: unrecognized HTTP method %q for route %q, methods must be uppercase, e.g. %q", method, path, http.MethodGet))
+		}
+	}
+}
+
+// Serve accepts HTTP connections on listener and serves them with Router.
+// This is useful for serving on a listener that was created elsewhere, e.g.
+// by socket activation, on an ephemeral port in tests, or inherited from a
+// parent process during a graceful restart.
+func (w *WebApp) Serve(listener net.Listener) error {
+	return w.newServer(listener).Serve(listener)
+}
+
+// ServeTLS is the same as Serve, but uses TLS (Transport Layer Security).
+func (w *WebApp) ServeTLS(listener net.Listener, certificatePath, keyPath string) error {
+	return w.newServer(listener).ServeTLS(listener, certificatePath, keyPath)
+}
+
+// newServer creates the server for serving on listener.
+func (w *WebApp) newServer(listener net.Listener) *http.Server {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.listener = listener
+	w.server = &http.Server{Handler: w.handler()}
+	return w.server
 }
 
 // Start starts the HTTP server.
 func (w *WebApp) Start() error {
-	serverAddress := w.serverHost + ":" + w.serverPort
-	return http.ListenAndServe(serverAddress, w.Router)
+	listener, err := net.Listen("tcp", w.serverHost+":"+w.serverPort)
+	if err != nil {
+		return err
+	}
+	return w.Serve(listener)
 }
 
 // StartWithTLS starts the HTTP server with TLS (Transport Layer Security).
 func (w *WebApp) StartWithTLS(certificatePath, keyPath string) error {
-	serverAddress := w.serverHost + ":" + w.serverPort
-	return http.ListenAndServeTLS(serverAddress, certificatePath, keyPath, w.Router)
+	listener, err := net.Listen("tcp", w.serverHost+":"+w.serverPort)
+	if err != nil {
+		return err
+	}
+	return w.ServeTLS(listener, certificatePath, keyPath)
 }
 
 func onError(writer http.ResponseWriter, request *http.Request, params httprouter.Params, err error) {
-	logger.Printf("error %s %s: %s", request.Method, request.URL, err)
+	logger.Printf("error %s%s %s: %s", logRequestID(writer), request.Method, request.URL, err)
+
+	// If the response was already started, e.g. streamed or hijacked, an
+	// error response would be appended to it.
+	if headerWritten(writer) {
+		return
+	}
 	http.Error(writer, "internal server error", http.StatusInternalServerError)
 }
 
 func onPanic(writer http.ResponseWriter, request *http.Request, params httprouter.Params, recoveryInfo interface{}) {
 	_, file, line, _ := runtime.Caller(4)
-	logger.Printf("panic %s %s: %s:%d %+v", request.Method, request.URL, path.Base(file), line, recoveryInfo)
+	logger.Printf("panic %s%s %s: %s:%d %+v", logRequestID(writer), request.Method, request.URL, path.Base(file), line, recoveryInfo)
+
+	// If the response was already started, an error response would be
+	// appended to it.
+	if headerWritten(writer) {
+		return
+	}
 	http.Error(writer, "internal server error", http.StatusInternalServerError)
 }
+
+// logRequestID returns the request ID followed by a space for log messages,
+// or an empty string if the request has no ID, see RequestIDMiddleware. The
+// ID is read from the response header, because OnError and OnPanic receive the
+// request without the context added by middlewares.
+func logRequestID(writer http.ResponseWriter) string {
+	if id := writer.Header().Get(RequestIDHeader); id != "" {
+		return "[" + id + "] "
+	}
+	return ""
+}
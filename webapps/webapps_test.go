@@ -0,0 +1,290 @@
+package webapps
+
+import (
+	"bufio"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestWebApp_Serve(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Creating listener failed unexpectedly: %s", err)
+	}
+
+	webApp := New("", "")
+	webApp.Route("/", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		_, err := writer.Write([]byte("foo"))
+		return err
+	}, "GET")
+
+	go webApp.Serve(listener)
+	defer listener.Close()
+
+	response, err := http.Get("http://" + listener.Addr().String() + "/")
+	if err != nil {
+		t.Fatalf("Request failed unexpectedly: %s", err)
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("Reading response failed unexpectedly: %s", err)
+	}
+
+	if expected := "foo"; string(body) != expected {
+		t.Errorf("Expected %q, got %q", expected, body)
+	}
+}
+
+func TestWebApp_Route_panic(t *testing.T) {
+	webApp := New("", "")
+	logger.SetOutput(ioutil.Discard)
+	defer logger.SetOutput(os.Stderr)
+
+	webApp.Route("/abort", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		panic(http.ErrAbortHandler)
+	}, "GET")
+	webApp.Route("/panic", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		panic("foo")
+	}, "GET")
+	webApp.Route("/panic-after-write", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		writer.Write([]byte("foo"))
+		panic("foo")
+	}, "GET")
+
+	// http.ErrAbortHandler must be passed on to net/http
+	func() {
+		defer func() {
+			if r := recover(); r != http.ErrAbortHandler {
+				t.Errorf("Expected panic with http.ErrAbortHandler, got %v", r)
+			}
+		}()
+		webApp.Router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/abort", nil))
+	}()
+
+	tests := []struct {
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{"/panic", http.StatusInternalServerError, "internal server error\n"},
+		{"/panic-after-write", http.StatusOK, "foo"},
+	}
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		webApp.Router.ServeHTTP(recorder, httptest.NewRequest("GET", test.path, nil))
+
+		if recorder.Code != test.expectedCode {
+			t.Errorf("%s: Expected status code %d, got %d", test.path, test.expectedCode, recorder.Code)
+		} else if body := recorder.Body.String(); body != test.expectedBody {
+			t.Errorf("%s: Expected body %q, got %q", test.path, test.expectedBody, body)
+		}
+	}
+}
+
+// hijackRecorder is an httptest.ResponseRecorder that supports hijacking.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (h hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, nil
+}
+
+func TestWebApp_Route_error(t *testing.T) {
+	webApp := New("", "")
+	logger.SetOutput(ioutil.Discard)
+	defer logger.SetOutput(os.Stderr)
+
+	webApp.Route("/error", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		return errors.New("foo")
+	}, "GET")
+	webApp.Route("/error-after-write", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		writer.Write([]byte("foo"))
+		return errors.New("foo")
+	}, "GET")
+	webApp.Route("/error-after-hijack", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		if _, _, err := http.NewResponseController(writer).Hijack(); err != nil {
+			return err
+		}
+		return errors.New("foo")
+	}, "GET")
+
+	tests := []struct {
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{"/error", http.StatusInternalServerError, "internal server error\n"},
+		{"/error-after-write", http.StatusOK, "foo"},
+		{"/error-after-hijack", http.StatusOK, ""},
+	}
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		webApp.Router.ServeHTTP(hijackRecorder{recorder}, httptest.NewRequest("GET", test.path, nil))
+
+		if recorder.Code != test.expectedCode {
+			t.Errorf("%s: Expected status code %d, got %d", test.path, test.expectedCode, recorder.Code)
+		} else if body := recorder.Body.String(); body != test.expectedBody {
+			t.Errorf("%s: Expected body %q, got %q", test.path, test.expectedBody, body)
+		}
+	}
+}
+
+func TestWebApp_Route_methods(t *testing.T) {
+	handle := func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		return nil
+	}
+
+	tests := []struct {
+		methods     []string
+		expectPanic bool
+	}{
+		{[]string{"GET", "POST"}, false},
+		{nil, true},
+		{[]string{"get"}, true},
+		{[]string{"GET", "FOO"}, true},
+	}
+
+	for i, test := range tests {
+		func() {
+			defer func() {
+				if r := recover(); (r != nil) != test.expectPanic {
+					t.Errorf("%d. Expected panic %t, got %v", i, test.expectPanic, r)
+				}
+			}()
+			New("", "").Route("/", handle, test.methods...)
+		}()
+	}
+}
+
+func TestWebApp_HandleHEAD(t *testing.T) {
+	handle := func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		writer.Header().Set("X-Foo", "bar")
+		_, err := writer.Write([]byte("foo"))
+		return err
+	}
+
+	webApp := New("", "")
+	webApp.Route("/off", handle, "GET")
+	webApp.HandleHEAD = true
+	webApp.Route("/on", handle, "GET")
+	webApp.Route("/own", handle, "GET", "HEAD")
+
+	tests := []struct {
+		method, path   string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"HEAD", "/off", http.StatusMethodNotAllowed, "Method Not Allowed\n"},
+		{"GET", "/on", http.StatusOK, "foo"},
+		{"HEAD", "/on", http.StatusOK, ""},
+		{"HEAD", "/own", http.StatusOK, "foo"},
+	}
+
+	for i, test := range tests {
+		recorder := httptest.NewRecorder()
+		webApp.Router.ServeHTTP(recorder, httptest.NewRequest(test.method, test.path, nil))
+
+		if recorder.Code != test.expectedStatus {
+			t.Errorf("%d. Expected status %d, got %d", i, test.expectedStatus, recorder.Code)
+		} else if body := recorder.Body.String(); body != test.expectedBody {
+			t.Errorf("%d. Expected body %q, got %q", i, test.expectedBody, body)
+		} else if test.expectedStatus == http.StatusOK && recorder.Header().Get("X-Foo") != "bar" {
+			t.Errorf("%d. Expected header to be set.", i)
+		}
+	}
+}
+
+func TestWebApp_Addr(t *testing.T) {
+	webApp := New("127.0.0.1", "0")
+	webApp.Route("/", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		_, err := writer.Write([]byte("foo"))
+		return err
+	}, "GET")
+
+	if addr := webApp.Addr(); addr != "" {
+		t.Errorf("Expected empty address before starting, got %q", addr)
+	}
+
+	go webApp.Start()
+
+	var addr string
+	for i := 0; i < 100 && addr == ""; i++ {
+		time.Sleep(10 * time.Millisecond)
+		addr = webApp.Addr()
+	}
+	if addr == "" {
+		t.Fatalf("Expected address after starting.")
+	}
+	defer webApp.listener.Close()
+
+	if strings.HasSuffix(addr, ":0") {
+		t.Errorf("Expected ephemeral port, got %q", addr)
+	}
+
+	response, err := http.Get("http://" + addr + "/")
+	if err != nil {
+		t.Fatalf("Request failed unexpectedly: %s", err)
+	}
+	defer response.Body.Close()
+
+	if body, err := ioutil.ReadAll(response.Body); err != nil {
+		t.Fatalf("Reading response failed unexpectedly: %s", err)
+	} else if expected := "foo"; string(body) != expected {
+		t.Errorf("Expected %q, got %q", expected, body)
+	}
+}
+
+func TestWebApp_Use(t *testing.T) {
+	var calls []string
+	middleware := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(writer, request)
+			})
+		}
+	}
+
+	webApp := New("", "")
+	webApp.Use(middleware("a"))
+	webApp.Use(middleware("b"))
+	webApp.Route("/", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		calls = append(calls, "handle")
+		return nil
+	}, "GET")
+
+	tests := []struct {
+		path          string
+		expectedCalls []string
+		expectedCode  int
+	}{
+		{"/", []string{"a", "b", "handle"}, http.StatusOK},
+		{"/missing", []string{"a", "b"}, http.StatusNotFound},
+	}
+
+	for i, test := range tests {
+		calls = nil
+		recorder := httptest.NewRecorder()
+		webApp.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", test.path, nil))
+
+		if strings.Join(calls, ",") != strings.Join(test.expectedCalls, ",") {
+			t.Errorf("%d. Expected calls %v, got %v", i, test.expectedCalls, calls)
+		} else if recorder.Code != test.expectedCode {
+			t.Errorf("%d. Expected status code %d, got %d", i, test.expectedCode, recorder.Code)
+		}
+	}
+}